@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/daniellawrence/ofx2json/ofx"
+)
+
+var csvHeader = []string{"date", "fitid", "type", "amount", "name", "memo"}
+
+// csvHeaderFields parses the comma separated Header=field pairs of
+// -csv-headers, e.g. "Date=date,Description=name,Amount=amount", into the
+// fields to write in that order, each named by its header.
+func csvHeaderFields(list string) ([]transactionField, error) {
+	var fields []transactionField
+	for _, pair := range strings.Split(list, ",") {
+		header, name, ok := strings.Cut(pair, "=")
+		header = strings.TrimSpace(header)
+		if !ok || header == "" {
+			return nil, fmt.Errorf("Invalid csv header: '%s', expected Header=field", strings.TrimSpace(pair))
+		}
+		selected, err := selectFields(name)
+		if err != nil {
+			return nil, err
+		}
+		f := selected[0]
+		f.name = header
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// writeCSV writes a header row followed by one row per transaction of every
+// statement, with a column for each of the fields.
+func writeCSV(w io.Writer, statements []*ofx.Ofx, fields []transactionField) error {
+	cw := csv.NewWriter(w)
+
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = f.name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, s := range statements {
+		for _, t := range s.Transactions {
+			row := make([]string, len(fields))
+			for i, f := range fields {
+				row[i] = f.value(t)
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
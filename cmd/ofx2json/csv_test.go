@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/daniellawrence/ofx2json/ofx"
+)
+
+func TestRunCSV(t *testing.T) {
+	const name = "../../ofx/testdata/quoting.ofx"
+
+	code, stdout, stderr := runCLI(t, "", "-format", "csv", name)
+	if code != 0 {
+		t.Fatalf("Wrong exit code. Expected: 0 Actual: %d (%s)\n", code, stderr)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(stdout)).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid csv output: %v\n%s\n", err, stdout)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	o, err := ofx.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{csvHeader}
+	for _, trans := range o.Transactions {
+		expected = append(expected, []string{
+			trans.PostedDateTime.Format("2006-01-02"), trans.FitID, trans.Type, trans.Amount.String(), trans.Name, trans.Memo,
+		})
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Wrong csv rows.\nExpected: %q\nActual:   %q\n", expected, rows)
+	}
+
+	if rows[1][5] != `Dinner, "the usual" at Joe's` {
+		t.Errorf("Memo did not survive quoting. Actual: %s\n", rows[1][5])
+	}
+}
+
+func TestRunUnknownFormat(t *testing.T) {
+	code, _, stderr := runCLI(t, "", "-format", "xls", fixture)
+	if code != 2 || !strings.Contains(stderr, "xls") {
+		t.Errorf("Expected exit code 2 naming the format. Actual: %d %s\n", code, stderr)
+	}
+}
+
+func TestRunCSVHeaders(t *testing.T) {
+	const name = "../../ofx/testdata/quoting.ofx"
+
+	code, stdout, stderr := runCLI(t, "", "-format", "csv", "-csv-headers", "Date=date, Description=name,Amount=amount", name)
+	if code != exitOK {
+		t.Fatalf("Wrong exit code. Expected: %d Actual: %d (%s)\n", exitOK, code, stderr)
+	}
+	expected := "Date,Description,Amount\n" +
+		"2019-01-05,\"JOE'S DINER, INC\",-61.20\n" +
+		"2019-01-09,RENT,-100.00\n" +
+		"2019-01-15,PAYROLL,2500.00\n"
+	if stdout != expected {
+		t.Errorf("Wrong csv output.\nExpected: %q\nActual:   %q\n", expected, stdout)
+	}
+
+	for _, args := range [][]string{
+		{"-format", "csv", "-csv-headers", "Date=posted"},
+		{"-format", "csv", "-csv-headers", "Date"},
+		{"-format", "csv", "-csv-headers", "=date"},
+		{"-csv-headers", "Date=date"},
+		{"-format", "csv", "-select", "date", "-csv-headers", "Date=date"},
+	} {
+		code, _, stderr := runCLI(t, "", append(args, name)...)
+		if code != exitUsage || stderr == "" {
+			t.Errorf("Expected exit code %d and an error for %v. Actual: %d %s\n", exitUsage, args, code, stderr)
+		}
+	}
+}
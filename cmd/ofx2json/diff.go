@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/daniellawrence/ofx2json/ofx"
+)
+
+// accountDiff is the diff output for one account.
+type accountDiff struct {
+	AccountBankNumber string `json:"account_bank_number"`
+	AccountNumber     string `json:"account_number"`
+	AccountType       string `json:"account_type"`
+	ofx.StatementDiff
+}
+
+// runDiff compares two downloads, OLD and NEW, writing what changed in each
+// account as JSON. The statements of each file are merged by account first,
+// and an account found in only one of them has every transaction added or
+// removed.
+func runDiff(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("ofx2json diff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	p := addParseFlags(flags)
+	pretty := flags.Bool("pretty", false, "indent the JSON output")
+	indentFlag := flags.String("indent", "", "indent the JSON output with this `indent`: a number of spaces, or \\t for a tab; implies -pretty (default two spaces)")
+	dates := flags.String("dates", "rfc3339", "JSON date format: rfc3339, date (YYYY-MM-DD) or unix (epoch seconds)")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() != 2 {
+		fmt.Fprintf(stderr, "Expected the OLD and NEW files to compare, got: %v\n", flags.Args())
+		return exitUsage
+	}
+	indent, err := parseIndent(*indentFlag, *pretty)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if err := setDates(*dates); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	opts, err := p.options()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	var files [2][]*ofx.Ofx
+	for i, path := range flags.Args() {
+		doc, err := parseFile(path, opts)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitInput
+		}
+		p.prepare(stderr, doc.Statements)
+		files[i] = ofx.Merge(doc.Statements)
+	}
+
+	if err := writeDiff(stdout, files[0], files[1], indent); err != nil {
+		fmt.Fprintf(stderr, "Failed to write diff, error: %v\n", err)
+		return exitUsage
+	}
+	return exitOK
+}
+
+// writeDiff writes the diff of each account of old and current, in order of
+// first appearance, as a JSON object for a single account or an array.
+func writeDiff(w io.Writer, old, current []*ofx.Ofx, indent string) error {
+	find := func(statements []*ofx.Ofx, s *ofx.Ofx) *ofx.Ofx {
+		for _, o := range statements {
+			if o.AccountType == s.AccountType && o.AccountBankNumber == s.AccountBankNumber && o.AccountNumber == s.AccountNumber {
+				return o
+			}
+		}
+		return nil
+	}
+
+	diffs := []accountDiff{}
+	add := func(s *ofx.Ofx, before, after *ofx.Ofx) {
+		if before == nil {
+			before = &ofx.Ofx{}
+		}
+		if after == nil {
+			after = &ofx.Ofx{}
+		}
+		diffs = append(diffs, accountDiff{
+			AccountBankNumber: s.AccountBankNumber,
+			AccountNumber:     s.AccountNumber,
+			AccountType:       s.AccountType,
+			StatementDiff:     before.Diff(after),
+		})
+	}
+	for _, s := range old {
+		add(s, s, find(current, s))
+	}
+	for _, s := range current {
+		if find(old, s) == nil {
+			add(s, nil, s)
+		}
+	}
+
+	var o interface{} = diffs
+	if len(diffs) == 1 {
+		o = diffs[0]
+	}
+
+	res, err := json.Marshal(o)
+	if err == nil && indent != "" {
+		var buf bytes.Buffer
+		err = json.Indent(&buf, res, "", indent)
+		res = buf.Bytes()
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(res, '\n'))
+	return err
+}
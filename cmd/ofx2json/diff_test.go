@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRunDiff(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "diff", "../../ofx/testdata/monthly-jan.ofx", "../../ofx/testdata/monthly-jan-redownload.ofx")
+	if code != exitOK {
+		t.Fatalf("Wrong exit code. Expected: %d Actual: %d (%s)\n", exitOK, code, stderr)
+	}
+
+	var d accountDiff
+	if err := json.Unmarshal([]byte(stdout), &d); err != nil {
+		t.Fatalf("Invalid diff: %v\n%s\n", err, stdout)
+	}
+	if d.AccountNumber != "7777" {
+		t.Errorf("Wrong account. Expected: 7777 Actual: %s\n", d.AccountNumber)
+	}
+	if len(d.Added) != 1 || d.Added[0].FitID != "M6" || len(d.Removed) != 1 || d.Removed[0].FitID != "M1" {
+		t.Errorf("Wrong added and removed transactions. Expected: [M6] [M1] Actual: %v %v\n", d.Added, d.Removed)
+	}
+	if len(d.Changed) != 1 || d.Changed[0].New.FitID != "M2" {
+		t.Errorf("Wrong changed transactions. Expected: [M2] Actual: %v\n", d.Changed)
+	}
+	if d.LedgerBalanceDelta.String() != "-14.00" {
+		t.Errorf("Wrong ledger balance delta. Expected: -14.00 Actual: %s\n", d.LedgerBalanceDelta)
+	}
+
+	// Accounts found in only one file are added or removed entirely.
+	_, stdout, _ = runCLI(t, "", "diff", fixture, "../../ofx/testdata/monthly-jan.ofx")
+	var diffs []accountDiff
+	if err := json.Unmarshal([]byte(stdout), &diffs); err != nil {
+		t.Fatalf("Invalid diff: %v\n%s\n", err, stdout)
+	}
+	if len(diffs) != 2 || len(diffs[0].Added) != 0 || len(diffs[0].Removed) == 0 || len(diffs[1].Added) != 2 {
+		t.Errorf("Wrong diffs of different accounts. Actual: %s\n", stdout)
+	}
+
+	if code, _, _ := runCLI(t, "", "diff", fixture); code != exitUsage {
+		t.Errorf("Wrong exit code for a single file. Expected: %d Actual: %d\n", exitUsage, code)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/daniellawrence/ofx2json/ofx"
+)
+
+const dateLayout = "2006-01-02"
+
+// filterDates keeps only the transactions, pending transactions and
+// investment transactions posted or traded on or between the since and
+// until dates, given as YYYY-MM-DD. Either bound may be empty. Dates are
+// compared on the calendar day of each transaction in its own time zone.
+func filterDates(statements []*ofx.Ofx, since, until string) error {
+	for _, bound := range []string{since, until} {
+		if bound == "" {
+			continue
+		}
+		if _, err := time.Parse(dateLayout, bound); err != nil {
+			return fmt.Errorf("Invalid date '%s', expected YYYY-MM-DD", bound)
+		}
+	}
+
+	if since == "" && until == "" {
+		return nil
+	}
+
+	inRange := func(t time.Time) bool {
+		day := t.Format(dateLayout)
+		return (since == "" || day >= since) && (until == "" || day <= until)
+	}
+	keep := func(transactions []*ofx.OfxTransaction) []*ofx.OfxTransaction {
+		kept := []*ofx.OfxTransaction{}
+		for _, t := range transactions {
+			if inRange(t.PostedDateTime) {
+				kept = append(kept, t)
+			}
+		}
+		return kept
+	}
+
+	for _, s := range statements {
+		s.Transactions = keep(s.Transactions)
+		if s.PendingTransactions != nil {
+			s.PendingTransactions = keep(s.PendingTransactions)
+		}
+		if s.InvestmentTransactions != nil {
+			kept := []*ofx.InvestmentTransaction{}
+			for _, t := range s.InvestmentTransactions {
+				if inRange(t.TradeDateTime) {
+					kept = append(kept, t)
+				}
+			}
+			s.InvestmentTransactions = kept
+		}
+	}
+	return nil
+}
+
+// accountTypes are the account types accepted by -accttype: the <ACCTTYPE>
+// codes of bank accounts, and those given to credit card and investment
+// statements.
+var accountTypes = []string{"CHECKING", "SAVINGS", "MONEYMRKT", "CREDITLINE", "CD", ofx.AccountTypeCreditCard, ofx.AccountTypeInvestment}
+
+// filterAccountType keeps only the statements of accounts of the given
+// type, matched case-insensitively. An empty type keeps every statement.
+func filterAccountType(statements []*ofx.Ofx, accountType string) ([]*ofx.Ofx, error) {
+	if accountType == "" {
+		return statements, nil
+	}
+
+	accountType = strings.ToUpper(accountType)
+	known := false
+	for _, t := range accountTypes {
+		known = known || t == accountType
+	}
+	if !known {
+		return nil, fmt.Errorf("Unknown account type '%s', expected one of %s", accountType, strings.Join(accountTypes, ", "))
+	}
+
+	kept := []*ofx.Ofx{}
+	for _, s := range statements {
+		if strings.ToUpper(s.AccountType) == accountType {
+			kept = append(kept, s)
+		}
+	}
+	return kept, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func fitIDs(t *testing.T, out string) []string {
+	var ids []string
+	for _, trans := range decodeStatement(t, out).Transactions {
+		ids = append(ids, trans.FitID)
+	}
+	return ids
+}
+
+func TestRunDateFilter(t *testing.T) {
+	const name = "../../ofx/testdata/quoting.ofx"
+
+	tests := []struct {
+		args     []string
+		expected []string
+		code     int
+	}{
+		{[]string{"-since", "2019-01-09"}, []string{"Q2", "Q3"}, exitOK},
+		{[]string{"-until", "2019-01-09"}, []string{"Q1", "Q2"}, exitOK},
+		{[]string{"-since", "2019-01-09", "-until", "2019-01-09"}, []string{"Q2"}, exitOK},
+		{[]string{"-since", "2019-02-01"}, nil, exitEmpty},
+	}
+
+	for _, test := range tests {
+		code, stdout, stderr := runCLI(t, "", append(test.args, name)...)
+		if code != test.code {
+			t.Fatalf("Wrong exit code for %v. Expected: %d Actual: %d (%s)\n", test.args, test.code, code, stderr)
+		}
+		if actual := fitIDs(t, stdout); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("Wrong transactions for %v. Expected: %v Actual: %v\n", test.args, test.expected, actual)
+		}
+	}
+
+	_, stdout, _ := runCLI(t, "", "-format", "csv", "-since", "2019-01-10", name)
+	if rows := strings.Count(stdout, "\n"); rows != 2 {
+		t.Errorf("Wrong number of csv lines. Expected: 2 Actual: %d\n%s\n", rows, stdout)
+	}
+}
+
+func TestRunDateFilterPendingAndInvestment(t *testing.T) {
+	_, stdout, stderr := runCLI(t, "", "-until", "2015-01-04", "../../ofx/testdata/pending.xml")
+	pending := decodeStatement(t, stdout).PendingTransactions
+	if len(pending) != 1 || pending[0].Name != "RESTAURANT" {
+		t.Errorf("Wrong pending transactions. Expected: RESTAURANT Actual: %+v (%s)\n", pending, stderr)
+	}
+
+	_, stdout, stderr = runCLI(t, "", "-since", "2014-06-10", "../../ofx/testdata/investment.ofx")
+	var ids []string
+	for _, trans := range decodeStatement(t, stdout).InvestmentTransactions {
+		ids = append(ids, trans.FitID)
+	}
+	if expected := []string{"23322", "23323"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Wrong investment transactions. Expected: %v Actual: %v (%s)\n", expected, ids, stderr)
+	}
+}
+
+func TestRunDateFilterInvalid(t *testing.T) {
+	code, _, stderr := runCLI(t, "", "-since", "01/09/2019", fixture)
+	if code != 2 || !strings.Contains(stderr, "YYYY-MM-DD") {
+		t.Errorf("Expected exit code 2 and a usage hint. Actual: %d %s\n", code, stderr)
+	}
+}
+
+func TestRunDedupe(t *testing.T) {
+	const name = "../../ofx/testdata/duplicates.ofx"
+
+	_, stdout, _ := runCLI(t, "", name)
+	if actual := fitIDs(t, stdout); len(actual) != 5 {
+		t.Errorf("Wrong number of transactions. Expected: 5 Actual: %d\n", len(actual))
+	}
+
+	_, stdout, _ = runCLI(t, "", "-dedupe", name)
+	if actual, expected := fitIDs(t, stdout), []string{"D1", "D2", "D3"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Wrong deduped transactions. Expected: %v Actual: %v\n", expected, actual)
+	}
+}
+
+func TestRunAccountType(t *testing.T) {
+	const name = "../../ofx/testdata/multi.ofx"
+
+	code, stdout, stderr := runCLI(t, "", "-accttype", "savings", name)
+	if code != exitOK {
+		t.Fatalf("Wrong exit code. Expected: %d Actual: %d (%s)\n", exitOK, code, stderr)
+	}
+	if actual := decodeStatement(t, stdout).AccountNumber; actual != "2222" {
+		t.Errorf("Wrong account number. Expected: %s Actual: %s\n", "2222", actual)
+	}
+
+	code, stdout, _ = runCLI(t, "", "-accttype", "CREDITCARD", name)
+	if code != exitEmpty || strings.TrimSpace(stdout) != "[]" {
+		t.Errorf("Expected no statements and exit code %d. Actual: %d %s\n", exitEmpty, code, stdout)
+	}
+
+	code, _, stderr = runCLI(t, "", "-accttype", "BROKERAGE", name)
+	if code != exitUsage || !strings.Contains(stderr, "CHECKING") {
+		t.Errorf("Expected exit code %d and the valid types. Actual: %d %s\n", exitUsage, code, stderr)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/daniellawrence/ofx2json/ofx"
+)
+
+// parseFlags are the flags, shared by every subcommand, that choose how the
+// OFX input is parsed.
+type parseFlags struct {
+	dedupe         *bool
+	strict         *bool
+	normalizeSigns *bool
+	excessPlaces   *string
+}
+
+func addParseFlags(flags *flag.FlagSet) *parseFlags {
+	return &parseFlags{
+		dedupe:         flags.Bool("dedupe", false, "drop transactions whose FITID was already seen in the statement"),
+		strict:         flags.Bool("strict", false, "fail on unknown transaction elements and malformed input instead of warning"),
+		normalizeSigns: flags.Bool("normalize-signs", false, "make debits such as DEBIT, FEE and CHECK negative and credits such as CREDIT and DEP positive, keeping the original in raw_amount"),
+		excessPlaces:   flags.String("excess-places", "truncate", "what to do with amount digits beyond the places of the currency, as in 12.345 USD: truncate, round or keep"),
+	}
+}
+
+// options returns the parser options chosen by the flags.
+func (p *parseFlags) options() ([]ofx.Option, error) {
+	var opts []ofx.Option
+	if *p.dedupe {
+		opts = append(opts, ofx.WithDedupe())
+	}
+	if *p.strict {
+		opts = append(opts, ofx.WithStrict())
+	}
+	if *p.normalizeSigns {
+		opts = append(opts, ofx.WithRawAmounts())
+	}
+	switch *p.excessPlaces {
+	case "truncate":
+	case "round":
+		opts = append(opts, ofx.WithExcessPlaces(ofx.RoundExcessPlaces))
+	case "keep":
+		opts = append(opts, ofx.WithExcessPlaces(ofx.KeepExcessPlaces))
+	default:
+		return nil, fmt.Errorf("Unknown excess places: '%s'", *p.excessPlaces)
+	}
+	return opts, nil
+}
+
+// prepare prints the warnings of the parsed statements to stderr and applies
+// -normalize-signs.
+func (p *parseFlags) prepare(stderr io.Writer, statements []*ofx.Ofx) {
+	for _, s := range statements {
+		for _, w := range s.Warnings {
+			fmt.Fprintf(stderr, "Warning: %s\n", w)
+		}
+	}
+
+	if *p.normalizeSigns {
+		for _, s := range statements {
+			s.NormalizeSigns()
+		}
+	}
+}
+
+// inputFlags are the flags of the parse, validate and summary subcommands,
+// which read statements from a file, an HTTP server or stdin and may combine
+// and filter them.
+type inputFlags struct {
+	*parseFlags
+	input       *string
+	url         *string
+	headers     headerFlags
+	timeout     *time.Duration
+	concat      *bool
+	merge       *bool
+	accountType *string
+	since       *string
+	until       *string
+	failEmpty   *bool
+}
+
+func addInputFlags(flags *flag.FlagSet) *inputFlags {
+	in := &inputFlags{
+		parseFlags:  addParseFlags(flags),
+		input:       flags.String("input", "", "path of the OFX file to read (default stdin)"),
+		url:         flags.String("url", "", "fetch the OFX document to read from this `url` with an HTTP GET"),
+		timeout:     flags.Duration("timeout", 30*time.Second, "give up on -url after this long"),
+		concat:      flags.Bool("concat", false, "read every file given as an argument and output all of their statements"),
+		merge:       flags.Bool("merge", false, "merge the statements of each account into one, dropping repeated FITIDs"),
+		accountType: flags.String("accttype", "", "only emit statements of this account `type`, e.g. CHECKING or SAVINGS"),
+		since:       flags.String("since", "", "only emit transactions posted on or after this `YYYY-MM-DD` date"),
+		until:       flags.String("until", "", "only emit transactions posted on or before this `YYYY-MM-DD` date"),
+		failEmpty:   flags.Bool("fail-empty", false, "write nothing and exit with code 3 when no statement holds any transaction"),
+	}
+	flags.Var(&in.headers, "header", "send this `Name: value` header with -url; may be repeated")
+	return in
+}
+
+// read parses the statements of -input, -url, the arguments left in flags or
+// stdin, prints their warnings and applies -normalize-signs, -merge,
+// -accttype, -since, -until and -fail-empty. When it fails it returns the
+// exit code.
+func (in *inputFlags) read(flags *flag.FlagSet, stdin io.Reader, stderr io.Writer) ([]*ofx.Ofx, int) {
+	var paths []string
+	if *in.input != "" {
+		paths = append(paths, *in.input)
+	}
+	paths = append(paths, flags.Args()...)
+	if len(paths) > 1 && !*in.concat {
+		fmt.Fprintf(stderr, "Expected a single input file, got: %v\n", paths)
+		return nil, exitUsage
+	}
+	if *in.url != "" && len(paths) > 0 {
+		fmt.Fprintf(stderr, "Expected either -url or input files, got: %v\n", paths)
+		return nil, exitUsage
+	}
+
+	opts, err := in.options()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return nil, exitUsage
+	}
+
+	var statements []*ofx.Ofx
+	switch {
+	case *in.url != "":
+		doc, err := fetchURL(*in.url, in.headers, *in.timeout, opts)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return nil, exitInput
+		}
+		statements = doc.Statements
+
+	case len(paths) == 0:
+		doc, err := ofx.ParseDocument(stdin, opts...)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to parse input, error: %v\n", err)
+			return nil, exitInput
+		}
+		statements = doc.Statements
+	}
+	for _, path := range paths {
+		doc, err := parseFile(path, opts)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return nil, exitInput
+		}
+		statements = append(statements, doc.Statements...)
+	}
+
+	in.prepare(stderr, statements)
+
+	if *in.merge {
+		statements = ofx.Merge(statements)
+	}
+
+	if statements, err = filterAccountType(statements, *in.accountType); err != nil {
+		fmt.Fprintln(stderr, err)
+		return nil, exitUsage
+	}
+
+	if err := filterDates(statements, *in.since, *in.until); err != nil {
+		fmt.Fprintln(stderr, err)
+		return nil, exitUsage
+	}
+
+	if *in.failEmpty && !hasTransactions(statements) {
+		fmt.Fprintln(stderr, "No transactions found: the input is valid OFX, but holds no transactions")
+		return nil, exitEmpty
+	}
+	return statements, exitOK
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/daniellawrence/ofx2json/ofx"
+)
+
+// jsonlContext identifies the account of a transaction in JSON Lines output.
+type jsonlContext struct {
+	AccountBankNumber string `json:"account_bank_number"`
+	AccountNumber     string `json:"account_number"`
+	AccountType       string `json:"account_type"`
+}
+
+// writeJSONL writes every transaction of every statement as a JSON object on
+// its own line, preceded by the account_bank_number, account_number and
+// account_type of its statement. Transactions are reduced to the given
+// fields unless fields is nil.
+func writeJSONL(w io.Writer, statements []*ofx.Ofx, fields []transactionField) error {
+	bw := bufio.NewWriter(w)
+
+	for _, s := range statements {
+		ctx, err := json.Marshal(jsonlContext{s.AccountBankNumber, s.AccountNumber, s.AccountType})
+		if err != nil {
+			return err
+		}
+
+		for _, t := range s.Transactions {
+			res, err := json.Marshal(t)
+			if err != nil {
+				return err
+			}
+			if fields != nil {
+				if res, err = projectTransaction(res, fields); err != nil {
+					return err
+				}
+			}
+
+			// Splice the two objects together: {context,transaction}.
+			bw.Write(ctx[:len(ctx)-1])
+			bw.WriteByte(',')
+			bw.Write(res[1:])
+			bw.WriteByte('\n')
+		}
+	}
+
+	return bw.Flush()
+}
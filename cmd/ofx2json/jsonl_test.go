@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/daniellawrence/ofx2json/ofx"
+)
+
+func TestRunJSONL(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "-format", "jsonl", "../../ofx/testdata/multi.ofx")
+	if code != 0 {
+		t.Fatalf("Wrong exit code. Expected: 0 Actual: %d (%s)\n", code, stderr)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Wrong number of lines. Expected: 3 Actual: %d\n%s\n", len(lines), stdout)
+	}
+
+	expected := []struct{ account, fitID string }{{"1111", "C1"}, {"1111", "C2"}, {"2222", "S1"}}
+	for i, line := range lines {
+		var trans ofx.OfxTransaction
+		if err := json.Unmarshal([]byte(line), &trans); err != nil {
+			t.Fatalf("Line %d is not a transaction: %v\n%s\n", i, err, line)
+		}
+		var context jsonlContext
+		if err := json.Unmarshal([]byte(line), &context); err != nil {
+			t.Fatal(err)
+		}
+
+		if trans.FitID != expected[i].fitID || context.AccountNumber != expected[i].account {
+			t.Errorf("Wrong line %d. Expected: %s/%s Actual: %s/%s\n", i, expected[i].account, expected[i].fitID, context.AccountNumber, trans.FitID)
+		}
+	}
+}
+
+func TestRunJSONLSelect(t *testing.T) {
+	_, stdout, _ := runCLI(t, "", "-format", "jsonl", "-select", "fitid,amount", fixture)
+
+	line := strings.SplitN(stdout, "\n", 2)[0]
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(line), &keys); err != nil {
+		t.Fatalf("Invalid json line: %v\n%s\n", err, line)
+	}
+	var actual []string
+	for k := range keys {
+		actual = append(actual, k)
+	}
+	expected := map[string]bool{"account_bank_number": true, "account_number": true, "account_type": true, "fit_id": true, "amount": true}
+	if len(keys) != len(expected) {
+		t.Errorf("Wrong keys. Actual: %v\n", actual)
+	}
+	for k := range keys {
+		if !expected[k] {
+			t.Errorf("Unexpected key %s in %v\n", k, actual)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+// Command ofx2json converts an OFX statement into JSON.
+//
+// The statement is read from the file given by -input or as the first
+// argument, from an HTTP server with -url, or from stdin when none is given;
+// -concat reads every file given as an argument. Files holding statements
+// for several accounts are emitted as a JSON array of statements.
+//
+// That is the parse subcommand, which runs when no other is named. The
+// validate and summary subcommands read statements the same way, and diff
+// compares two files:
+//
+//	ofx2json [parse] [flags] [file...]
+//	ofx2json validate [flags] [file...]
+//	ofx2json summary [flags] [file...]
+//	ofx2json diff [flags] old new
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/daniellawrence/ofx2json/ofx"
+)
+
+// Exit codes. Output is written for exitEmpty and exitInvalid, so scripts
+// can still use it.
+const (
+	exitOK = 0
+	// exitInput means the input could not be read or is not valid OFX.
+	exitInput = 1
+	// exitUsage means bad flags, or that the output could not be written.
+	exitUsage = 2
+	// exitEmpty means no statement holds any transaction.
+	exitEmpty = 3
+	// exitInvalid means -validate found problems, which are listed on
+	// stderr.
+	exitInvalid = 4
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// commands are the subcommands of ofx2json, named by its first argument.
+// Without one it runs parse.
+var commands = map[string]func(args []string, stdin io.Reader, stdout, stderr io.Writer) int{
+	"parse":    runParse,
+	"validate": runValidate,
+	"summary":  runSummary,
+	"diff":     runDiff,
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		if cmd, ok := commands[args[0]]; ok {
+			return cmd(args[1:], stdin, stdout, stderr)
+		}
+	}
+	return runParse(args, stdin, stdout, stderr)
+}
+
+// runParse converts the statements to JSON or another -format.
+func runParse(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("ofx2json parse", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	in := addInputFlags(flags)
+	pretty := flags.Bool("pretty", false, "indent the JSON output")
+	indentFlag := flags.String("indent", "", "indent the JSON output with this `indent`: a number of spaces, or \\t for a tab; implies -pretty (default two spaces)")
+	format := flags.String("format", "json", "output format: json, jsonl, csv or qif")
+	dates := flags.String("dates", "rfc3339", "JSON date format: rfc3339, date (YYYY-MM-DD) or unix (epoch seconds)")
+	selected := flags.String("select", "", "comma separated transaction `fields` to output, e.g. date,amount,memo")
+	csvHeaders := flags.String("csv-headers", "", "comma separated `Header=field` pairs naming the -format csv columns, e.g. Date=date,Description=name,Amount=amount")
+	templateText := flags.String("template", "", "write each transaction with this Go text/`template`, e.g. '{{.PostedDateTime.Format \"2006-01-02\"}} {{.Amount}} {{.Name}}'")
+	templateScope := flags.String("template-scope", "transaction", "execute -template once per transaction or once per statement")
+	showSummary := flags.Bool("summary", false, "output the number of transactions, credit, debit and net totals, date range and totals per type of each statement, as JSON")
+	validate := flags.Bool("validate", false, "check each statement for missing account ids, duplicate FITIDs and out of period transactions")
+	redact := flags.Bool("redact", false, "replace account numbers, bank numbers and FITIDs with placeholders, for sharing statements")
+	showVersion := flags.Bool("version", false, "print the version and exit")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if *showVersion {
+		fmt.Fprintf(stdout, "ofx2json %s\n", buildVersion())
+		return exitOK
+	}
+
+	statements, code := in.read(flags, stdin, stderr)
+	if code != exitOK {
+		return code
+	}
+
+	if *redact {
+		r, err := newRedactor()
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to redact, error: %v\n", err)
+			return exitUsage
+		}
+		r.redact(statements)
+	}
+
+	if err := setDates(*dates); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	indent, err := parseIndent(*indentFlag, *pretty)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	var fields []transactionField
+	if *selected != "" {
+		if *format == "qif" {
+			fmt.Fprintln(stderr, "-select is not supported with -format qif")
+			return exitUsage
+		}
+		if fields, err = selectFields(*selected); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+
+	if *csvHeaders != "" {
+		if *format != "csv" || fields != nil {
+			fmt.Fprintln(stderr, "-csv-headers requires -format csv and can not be combined with -select")
+			return exitUsage
+		}
+		if fields, err = csvHeaderFields(*csvHeaders); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+
+	if *templateText != "" {
+		if *format != "json" || fields != nil || *showSummary {
+			fmt.Fprintln(stderr, "-template can not be combined with -format, -select or -summary")
+			return exitUsage
+		}
+		if *templateScope != "transaction" && *templateScope != "statement" {
+			fmt.Fprintf(stderr, "Unknown template scope: '%s'\n", *templateScope)
+			return exitUsage
+		}
+		tmpl, err := parseTemplate(*templateText)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		if err := writeTemplate(stdout, tmpl, *templateScope, statements); err != nil {
+			fmt.Fprintf(stderr, "Failed to write template, error: %v\n", err)
+			return exitUsage
+		}
+		return outcome(stderr, statements, *validate)
+	}
+
+	if *showSummary {
+		if *format != "json" || fields != nil {
+			fmt.Fprintln(stderr, "-summary can not be combined with -format or -select")
+			return exitUsage
+		}
+		if err := writeSummary(stdout, statements, indent); err != nil {
+			fmt.Fprintf(stderr, "Failed to write summary, error: %v\n", err)
+			return exitUsage
+		}
+		return outcome(stderr, statements, *validate)
+	}
+
+	if code := writeOutput(stdout, stderr, *format, statements, indent, fields); code != exitOK {
+		return code
+	}
+	return outcome(stderr, statements, *validate)
+}
+
+// runValidate checks the statements for the problems listed by
+// ofx.Ofx.Validate, printing them to stderr, without writing any output.
+func runValidate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("ofx2json validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	in := addInputFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	statements, code := in.read(flags, stdin, stderr)
+	if code != exitOK {
+		return code
+	}
+	return outcome(stderr, statements, true)
+}
+
+// setDates sets the JSON date format chosen by -dates.
+func setDates(dates string) error {
+	switch dates {
+	case "rfc3339":
+		ofx.JSONDateFormat = ofx.DateRFC3339
+	case "date":
+		ofx.JSONDateFormat = ofx.DateOnly
+	case "unix":
+		ofx.JSONDateFormat = ofx.DateUnix
+	default:
+		return fmt.Errorf("Unknown date format: '%s'", dates)
+	}
+	return nil
+}
+
+// writeOutput writes the statements in the given format.
+func writeOutput(stdout, stderr io.Writer, format string, statements []*ofx.Ofx, indent string, fields []transactionField) int {
+	switch format {
+	case "json":
+		return writeJSON(stdout, stderr, statements, indent, fields)
+
+	case "jsonl":
+		if err := writeJSONL(stdout, statements, fields); err != nil {
+			fmt.Fprintf(stderr, "Failed to write jsonl, error: %v\n", err)
+			return exitUsage
+		}
+		return exitOK
+
+	case "csv":
+		if fields == nil {
+			fields, _ = selectFields(strings.Join(csvHeader, ","))
+		}
+		if err := writeCSV(stdout, statements, fields); err != nil {
+			fmt.Fprintf(stderr, "Failed to write csv, error: %v\n", err)
+			return exitUsage
+		}
+		return exitOK
+
+	case "qif":
+		if err := writeQIF(stdout, statements); err != nil {
+			fmt.Fprintf(stderr, "Failed to write qif, error: %v\n", err)
+			return exitUsage
+		}
+		return exitOK
+
+	default:
+		fmt.Fprintf(stderr, "Unknown output format: '%s'\n", format)
+		return exitUsage
+	}
+}
+
+// outcome returns the exit code for output that was written successfully:
+// exitInvalid when validate is set and a statement fails Validate, and
+// otherwise exitEmpty when no statement holds any transaction.
+func outcome(stderr io.Writer, statements []*ofx.Ofx, validate bool) int {
+	if validate {
+		invalid := false
+		for _, s := range statements {
+			for _, err := range s.Validate() {
+				fmt.Fprintf(stderr, "Account '%s': %v\n", s.AccountNumber, err)
+				invalid = true
+			}
+		}
+		if invalid {
+			return exitInvalid
+		}
+	}
+
+	if hasTransactions(statements) {
+		return exitOK
+	}
+	fmt.Fprintln(stderr, "No transactions found")
+	return exitEmpty
+}
+
+// hasTransactions reports whether any of the statements holds a
+// transaction.
+func hasTransactions(statements []*ofx.Ofx) bool {
+	for _, s := range statements {
+		if len(s.Transactions) > 0 || len(s.InvestmentTransactions) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFile parses the OFX file at path.
+func parseFile(path string, opts []ofx.Option) (*ofx.OfxDocument, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open input, error: %w", err)
+	}
+	defer f.Close()
+
+	doc, err := ofx.ParseDocument(f, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse input %s, error: %w", path, err)
+	}
+	return doc, nil
+}
+
+// writeJSON writes the statements, reducing each transaction to the given
+// fields unless fields is nil, and indenting the output with indent unless
+// it is empty.
+func writeJSON(stdout, stderr io.Writer, statements []*ofx.Ofx, indent string, fields []transactionField) int {
+	// A single statement is emitted as an object, several as an array.
+	var buf bytes.Buffer
+	var err error
+	if len(statements) == 1 {
+		err = statements[0].WriteJSON(&buf)
+	} else {
+		err = json.NewEncoder(&buf).Encode(statements)
+	}
+
+	res := bytes.TrimSpace(buf.Bytes())
+	if err == nil && fields != nil {
+		res, err = projectJSON(res, fields)
+	}
+	if err == nil && indent != "" {
+		var indented bytes.Buffer
+		err = json.Indent(&indented, res, "", indent)
+		res = indented.Bytes()
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to Marshal into json, error: %v\n", err)
+		return exitUsage
+	}
+
+	fmt.Fprintln(stdout, string(res))
+	return exitOK
+}
+
+// parseIndent returns the indentation of the JSON output for -indent and
+// -pretty: "" for compact output, two spaces for -pretty alone, and
+// otherwise the -indent given as a number of spaces or as \t for a tab.
+func parseIndent(indent string, pretty bool) (string, error) {
+	switch {
+	case indent == "" && pretty:
+		return "  ", nil
+	case indent == "":
+		return "", nil
+	case indent == `\t` || indent == "\t":
+		return "\t", nil
+	}
+
+	n, err := strconv.Atoi(indent)
+	if err != nil || n < 1 || n > 16 {
+		return "", fmt.Errorf("Invalid indent: '%s', expected a number of spaces from 1 to 16 or \\t", indent)
+	}
+	return strings.Repeat(" ", n), nil
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daniellawrence/ofx2json/ofx"
+)
+
+const fixture = "../../ofx/testdata/v103.ofx"
+
+// runCLI invokes the command with args and returns its exit code, stdout and
+// stderr.
+func runCLI(t *testing.T, stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// tempFixture copies the named fixture into a temporary directory and
+// returns the path of the copy.
+func tempFixture(t *testing.T, name string) string {
+	bts, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), filepath.Base(name))
+	if err := ioutil.WriteFile(path, bts, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func decodeStatement(t *testing.T, out string) *ofx.Ofx {
+	var o ofx.Ofx
+	if err := json.Unmarshal([]byte(out), &o); err != nil {
+		t.Fatalf("Invalid json output: %v\n%s\n", err, out)
+	}
+	return &o
+}
+
+func TestRunInputFile(t *testing.T) {
+	path := tempFixture(t, fixture)
+
+	for _, args := range [][]string{{path}, {"-input", path}} {
+		code, stdout, stderr := runCLI(t, "", args...)
+		if code != 0 {
+			t.Fatalf("Wrong exit code for %v. Expected: 0 Actual: %d (%s)\n", args, code, stderr)
+		}
+
+		o := decodeStatement(t, stdout)
+		if o.AccountNumber != "098-121" {
+			t.Errorf("Wrong account number. Expected: %s Actual: %s\n", "098-121", o.AccountNumber)
+		}
+	}
+}
+
+func TestRunStdin(t *testing.T) {
+	bts, err := ioutil.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := runCLI(t, string(bts))
+	if code != 0 {
+		t.Fatalf("Wrong exit code. Expected: 0 Actual: %d (%s)\n", code, stderr)
+	}
+	if o := decodeStatement(t, stdout); len(o.Transactions) != 3 {
+		t.Errorf("Wrong number of transactions. Expected: 3 Actual: %d\n", len(o.Transactions))
+	}
+}
+
+func TestRunMissingInput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.ofx")
+
+	code, stdout, stderr := runCLI(t, "", path)
+	if code != 1 {
+		t.Errorf("Wrong exit code. Expected: 1 Actual: %d\n", code)
+	}
+	if stdout != "" {
+		t.Errorf("Unexpected output: %s\n", stdout)
+	}
+	if !strings.Contains(stderr, "missing.ofx") {
+		t.Errorf("Error does not mention the missing file: %s\n", stderr)
+	}
+}
+
+func TestRunPretty(t *testing.T) {
+	path := tempFixture(t, fixture)
+
+	_, compact, _ := runCLI(t, "", path)
+	if strings.Count(compact, "\n") != 1 {
+		t.Errorf("Expected compact output on a single line. Actual: %s\n", compact)
+	}
+
+	code, pretty, stderr := runCLI(t, "", "-pretty", path)
+	if code != 0 {
+		t.Fatalf("Wrong exit code. Expected: 0 Actual: %d (%s)\n", code, stderr)
+	}
+	if !strings.Contains(pretty, "\n  \"account_number\": \"098-121\"") {
+		t.Errorf("Expected two-space indented output. Actual: %s\n", pretty)
+	}
+	decodeStatement(t, pretty)
+}
+
+func TestRunIndent(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"-indent", "4"}, "\n    \"account_number\": \"098-121\""},
+		{[]string{"-indent", `\t`}, "\n\t\"account_number\": \"098-121\""},
+		{[]string{"-pretty", "-indent", "1"}, "\n \"account_number\": \"098-121\""},
+		{[]string{"-summary", "-indent", `\t`}, "\n\t\"account_number\": \"098-121\""},
+	}
+
+	for _, test := range tests {
+		code, stdout, stderr := runCLI(t, "", append(test.args, fixture)...)
+		if code != exitOK {
+			t.Fatalf("Wrong exit code for %v. Expected: %d Actual: %d (%s)\n", test.args, exitOK, code, stderr)
+		}
+		if !strings.Contains(stdout, test.expected) {
+			t.Errorf("Expected %q in the output for %v. Actual: %s\n", test.expected, test.args, stdout)
+		}
+	}
+
+	for _, indent := range []string{"0", "-2", "two", "17"} {
+		if code, _, _ := runCLI(t, "", "-indent", indent, fixture); code != exitUsage {
+			t.Errorf("Wrong exit code for -indent %s. Expected: %d Actual: %d\n", indent, exitUsage, code)
+		}
+	}
+}
+
+func TestRunMultipleStatements(t *testing.T) {
+	path := tempFixture(t, "../../ofx/testdata/multi.ofx")
+
+	code, stdout, stderr := runCLI(t, "", path)
+	if code != 0 {
+		t.Fatalf("Wrong exit code. Expected: 0 Actual: %d (%s)\n", code, stderr)
+	}
+
+	var statements []*ofx.Ofx
+	if err := json.Unmarshal([]byte(stdout), &statements); err != nil {
+		t.Fatalf("Expected an array of statements: %v\n%s\n", err, stdout)
+	}
+	if len(statements) != 2 {
+		t.Fatalf("Wrong number of statements. Expected: 2 Actual: %d\n", len(statements))
+	}
+	if statements[1].AccountNumber != "2222" {
+		t.Errorf("Wrong account number. Expected: %s Actual: %s\n", "2222", statements[1].AccountNumber)
+	}
+}
+
+func TestRunDates(t *testing.T) {
+	tests := []struct {
+		dates    string
+		expected string
+	}{
+		{"rfc3339", `"posted_datetime":"2007-03-15T00:00:00Z"`},
+		{"date", `"posted_datetime":"2007-03-15"`},
+		{"unix", `"posted_datetime":1173916800`},
+	}
+	for _, test := range tests {
+		code, stdout, stderr := runCLI(t, "", "-dates", test.dates, fixture)
+		if code != 0 {
+			t.Fatalf("Wrong exit code for -dates %s. Expected: 0 Actual: %d (%s)\n", test.dates, code, stderr)
+		}
+		if !strings.Contains(stdout, test.expected) {
+			t.Errorf("Expected %s in output for -dates %s\n", test.expected, test.dates)
+		}
+	}
+
+	if code, _, _ := runCLI(t, "", "-dates", "bogus", fixture); code != 2 {
+		t.Errorf("Wrong exit code for an unknown date format. Expected: 2 Actual: %d\n", code)
+	}
+}
+
+func TestRunGzipInput(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "../../ofx/testdata/v103.ofx.gz")
+	if code != 0 {
+		t.Fatalf("Wrong exit code. Expected: 0 Actual: %d (%s)\n", code, stderr)
+	}
+	if o := decodeStatement(t, stdout); o.AccountNumber != "098-121" || len(o.Transactions) != 3 {
+		t.Errorf("Wrong statement. Expected: 098-121 with 3 transactions Actual: %s with %d\n", o.AccountNumber, len(o.Transactions))
+	}
+}
+
+func TestRunConcat(t *testing.T) {
+	files := []string{
+		"../../ofx/testdata/monthly-jan.ofx",
+		"../../ofx/testdata/monthly-feb.ofx",
+		"../../ofx/testdata/monthly-mar.ofx",
+	}
+
+	code, _, stderr := runCLI(t, "", files...)
+	if code != 2 || !strings.Contains(stderr, "single input file") {
+		t.Errorf("Expected several files to need -concat. Actual: %d %s\n", code, stderr)
+	}
+
+	code, stdout, stderr := runCLI(t, "", append([]string{"-concat"}, files...)...)
+	if code != 0 {
+		t.Fatalf("Wrong exit code. Expected: 0 Actual: %d (%s)\n", code, stderr)
+	}
+	var statements []*ofx.Ofx
+	if err := json.Unmarshal([]byte(stdout), &statements); err != nil {
+		t.Fatalf("Invalid json output: %v\n%s\n", err, stdout)
+	}
+	if len(statements) != 3 {
+		t.Errorf("Wrong number of statements. Expected: 3 Actual: %d\n", len(statements))
+	}
+
+	code, stdout, stderr = runCLI(t, "", append([]string{"-concat", "-merge"}, files...)...)
+	if code != 0 {
+		t.Fatalf("Wrong exit code. Expected: 0 Actual: %d (%s)\n", code, stderr)
+	}
+	if o := decodeStatement(t, stdout); len(o.Transactions) != 5 || o.AccountNumber != "7777" {
+		t.Errorf("Wrong merged statement. Expected: 7777 with 5 transactions Actual: %s with %d\n", o.AccountNumber, len(o.Transactions))
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		expected int
+	}{
+		{"success", []string{fixture}, "", exitOK},
+		{"valid", []string{"-validate", fixture}, "", exitOK},
+		{"missing input", []string{"does-not-exist.ofx"}, "", exitInput},
+		{"not ofx", []string{"../../ofx/testdata/login.html"}, "", exitInput},
+		{"bad flag", []string{"-bogus", fixture}, "", exitUsage},
+		{"no transactions", nil, "<OFX><BANKTRANLIST></BANKTRANLIST></OFX>", exitEmpty},
+		{"validation failure", []string{"-validate", "../../ofx/testdata/duplicates.ofx"}, "", exitInvalid},
+		{"duplicates without -validate", []string{"../../ofx/testdata/duplicates.ofx"}, "", exitOK},
+	}
+	for _, test := range tests {
+		code, stdout, stderr := runCLI(t, test.stdin, test.args...)
+		if code != test.expected {
+			t.Errorf("Wrong exit code for %s. Expected: %d Actual: %d (%s)\n", test.name, test.expected, code, stderr)
+		}
+		if (code == exitEmpty || code == exitInvalid) && stdout == "" {
+			t.Errorf("Expected output to still be written for %s\n", test.name)
+		}
+	}
+
+	_, _, stderr := runCLI(t, "", "-validate", "../../ofx/testdata/duplicates.ofx")
+	if !strings.Contains(stderr, "Duplicate FITID") {
+		t.Errorf("Expected the validation problems on stderr. Actual: %s\n", stderr)
+	}
+}
+
+func TestRunFailEmpty(t *testing.T) {
+	const empty = "<OFX><BANKTRANLIST></BANKTRANLIST></OFX>"
+
+	code, stdout, stderr := runCLI(t, empty, "-fail-empty")
+	if code != exitEmpty || stdout != "" || !strings.Contains(stderr, "valid OFX, but holds no transactions") {
+		t.Errorf("Expected exit code %d, no output and the reason. Actual: %d %q %q\n", exitEmpty, code, stdout, stderr)
+	}
+
+	// Transactions filtered out by -since count as none.
+	code, stdout, _ = runCLI(t, "", "-fail-empty", "-since", "2030-01-01", fixture)
+	if code != exitEmpty || stdout != "" {
+		t.Errorf("Wrong result with every transaction filtered out. Expected: %d Actual: %d %q\n", exitEmpty, code, stdout)
+	}
+
+	code, stdout, stderr = runCLI(t, "", "-fail-empty", fixture)
+	if code != exitOK || stdout == "" || stderr != "" {
+		t.Errorf("Expected the statement to be written. Actual: %d %q %q\n", code, stdout, stderr)
+	}
+
+	// A parse failure is still reported as one.
+	code, _, stderr = runCLI(t, "not ofx", "-fail-empty")
+	if code != exitInput || strings.Contains(stderr, "No transactions found") {
+		t.Errorf("Expected exit code %d for invalid input. Actual: %d %s\n", exitInput, code, stderr)
+	}
+}
+
+func TestRunSubcommands(t *testing.T) {
+	_, bare, _ := runCLI(t, "", fixture)
+	code, stdout, stderr := runCLI(t, "", "parse", fixture)
+	if code != exitOK || stdout != bare {
+		t.Errorf("Expected parse to match a bare invocation. Actual: %d %s (%s)\n", code, stdout, stderr)
+	}
+
+	code, stdout, stderr = runCLI(t, "", "validate", fixture)
+	if code != exitOK || stdout != "" || stderr != "" {
+		t.Errorf("Expected validate to pass silently. Actual: %d %q %q\n", code, stdout, stderr)
+	}
+	code, stdout, stderr = runCLI(t, "", "validate", "../../ofx/testdata/duplicates.ofx")
+	if code != exitInvalid || stdout != "" || !strings.Contains(stderr, "Duplicate FITID") {
+		t.Errorf("Expected validate to report the duplicates. Actual: %d %q %q\n", code, stdout, stderr)
+	}
+
+	_, expected, _ := runCLI(t, "", "-summary", fixture)
+	code, stdout, stderr = runCLI(t, "", "summary", fixture)
+	if code != exitOK || stdout != expected {
+		t.Errorf("Expected summary to match -summary.\nExpected: %s\nActual:   %s (%d %s)\n", expected, stdout, code, stderr)
+	}
+
+	if code, _, _ := runCLI(t, "", "summary", "-format", "csv", fixture); code != exitUsage {
+		t.Errorf("Expected summary to reject parse flags. Actual: %d\n", code)
+	}
+}
+
+func TestRunWarnings(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "../../ofx/testdata/unknown.ofx")
+	if code != 0 {
+		t.Fatalf("Wrong exit code. Expected: 0 Actual: %d (%s)\n", code, stderr)
+	}
+	if !strings.Contains(stderr, "Warning: ") || !strings.Contains(stderr, "<LOYALTYPOINTS>") {
+		t.Errorf("Expected the warnings on stderr. Actual: %s\n", stderr)
+	}
+	if o := decodeStatement(t, stdout); len(o.Warnings) != 2 {
+		t.Errorf("Wrong number of warnings in the output. Expected: 2 Actual: %q\n", o.Warnings)
+	}
+}
+
+func TestRunStrict(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "-strict", "../../ofx/testdata/unknown.ofx")
+	if code != exitInput || stdout != "" {
+		t.Errorf("Wrong exit code. Expected: %d and no output Actual: %d %s\n", exitInput, code, stdout)
+	}
+	if !strings.Contains(stderr, "<LOYALTYPOINTS>") {
+		t.Errorf("Expected the problem on stderr. Actual: %s\n", stderr)
+	}
+
+	if code, _, stderr := runCLI(t, "", "-strict", fixture); code != exitOK {
+		t.Errorf("Wrong exit code for clean input. Expected: %d Actual: %d (%s)\n", exitOK, code, stderr)
+	}
+}
+
+func TestRunNormalizeSigns(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "-normalize-signs", "../../ofx/testdata/positive.ofx")
+	if code != exitOK {
+		t.Fatalf("Wrong exit code. Expected: %d Actual: %d (%s)\n", exitOK, code, stderr)
+	}
+
+	trans := decodeStatement(t, stdout).Transactions
+	if trans[0].Amount.String() != "-12.00" || trans[0].RawAmount != "12.00" {
+		t.Errorf("Wrong DEBIT amount. Expected: -12.00 (raw 12.00) Actual: %s (raw %s)\n", trans[0].Amount, trans[0].RawAmount)
+	}
+	if trans[4].Amount.String() != "40.00" || trans[4].RawAmount != "-40.00" {
+		t.Errorf("Wrong DEP amount. Expected: 40.00 (raw -40.00) Actual: %s (raw %s)\n", trans[4].Amount, trans[4].RawAmount)
+	}
+}
+
+func TestRunExcessPlaces(t *testing.T) {
+	const name = "../../ofx/testdata/excess.ofx"
+
+	for mode, expected := range map[string]string{"truncate": "12.34", "round": "12.35", "keep": "12.345"} {
+		code, stdout, stderr := runCLI(t, "", "-excess-places", mode, name)
+		if code != exitOK {
+			t.Fatalf("Wrong exit code for %s. Expected: %d Actual: %d (%s)\n", mode, exitOK, code, stderr)
+		}
+		if actual := decodeStatement(t, stdout).Transactions[0].Amount.String(); actual != expected {
+			t.Errorf("Wrong amount for %s. Expected: %s Actual: %s\n", mode, expected, actual)
+		}
+	}
+
+	if code, _, _ := runCLI(t, "", "-excess-places", "ceil", name); code != exitUsage {
+		t.Errorf("Wrong exit code. Expected: %d Actual: %d\n", exitUsage, code)
+	}
+}
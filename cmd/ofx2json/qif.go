@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/daniellawrence/ofx2json/ofx"
+)
+
+// writeQIF writes each statement as a QIF account block with one
+// ^-terminated record per transaction.
+func writeQIF(w io.Writer, statements []*ofx.Ofx) error {
+	bw := bufio.NewWriter(w)
+
+	for _, s := range statements {
+		if s.AccountType == ofx.AccountTypeCreditCard {
+			bw.WriteString("!Type:CCard\n")
+		} else {
+			bw.WriteString("!Type:Bank\n")
+		}
+
+		for _, t := range s.Transactions {
+			qifLine(bw, 'D', t.PostedDateTime.Format("01/02/2006"))
+			qifLine(bw, 'T', t.Amount.String())
+			qifLine(bw, 'N', t.CheckNum)
+			qifLine(bw, 'P', t.Name)
+			qifLine(bw, 'M', t.Memo)
+			bw.WriteString("^\n")
+		}
+	}
+
+	return bw.Flush()
+}
+
+// qifLine writes a single QIF field, skipping empty values. QIF fields
+// cannot span lines, so any line breaks in the value are folded into spaces.
+func qifLine(w *bufio.Writer, code byte, value string) {
+	if value == "" {
+		return
+	}
+	w.WriteByte(code)
+	w.WriteString(strings.Join(strings.Fields(value), " "))
+	w.WriteByte('\n')
+}
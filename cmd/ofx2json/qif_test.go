@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+type qifRecord map[byte]string
+
+// readQIF splits QIF output into its type line and records.
+func readQIF(t *testing.T, out string) (string, []qifRecord) {
+	var header string
+	var records []qifRecord
+	record := qifRecord{}
+
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "!"):
+			header = line
+		case line == "^":
+			records = append(records, record)
+			record = qifRecord{}
+		case line != "":
+			record[line[0]] = line[1:]
+		}
+	}
+	if len(record) != 0 {
+		t.Errorf("Unterminated QIF record: %v\n", record)
+	}
+	return header, records
+}
+
+func TestRunQIF(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "-format", "qif", "../../ofx/testdata/quoting.ofx")
+	if code != 0 {
+		t.Fatalf("Wrong exit code. Expected: 0 Actual: %d (%s)\n", code, stderr)
+	}
+
+	header, records := readQIF(t, stdout)
+	if header != "!Type:Bank" {
+		t.Errorf("Wrong QIF type. Expected: !Type:Bank Actual: %s\n", header)
+	}
+
+	expected := []qifRecord{
+		{'D': "01/05/2019", 'T': "-61.20", 'P': "JOE'S DINER, INC", 'M': `Dinner, "the usual" at Joe's`},
+		{'D': "01/09/2019", 'T': "-100.00", 'N': "311", 'P': "RENT", 'M': "January"},
+		{'D': "01/15/2019", 'T': "2500.00", 'P': "PAYROLL"},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("Wrong number of records. Expected: %d Actual: %d\n%s\n", len(expected), len(records), stdout)
+	}
+	for i, e := range expected {
+		for code, value := range e {
+			if records[i][code] != value {
+				t.Errorf("Wrong %c in record %d. Expected: %s Actual: %s\n", code, i, value, records[i][code])
+			}
+		}
+	}
+}
+
+func TestRunQIFCreditCard(t *testing.T) {
+	_, stdout, _ := runCLI(t, "", "-format", "qif", "../../ofx/testdata/creditcard.ofx")
+	if header, records := readQIF(t, stdout); header != "!Type:CCard" || len(records) != 2 {
+		t.Errorf("Expected a CCard block with 2 records. Actual: %s %d\n", header, len(records))
+	}
+}
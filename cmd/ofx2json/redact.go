@@ -0,0 +1,73 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/daniellawrence/ofx2json/ofx"
+)
+
+// redactor replaces identifying values with placeholders, for sharing
+// statements in bug reports. The placeholders are keyed hashes: the same
+// value always gets the same placeholder within a run, so references stay
+// consistent, while the random key keeps short values such as account
+// numbers from being recovered by hashing every candidate.
+type redactor struct {
+	key []byte
+}
+
+func newRedactor() (*redactor, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return &redactor{key: key}, nil
+}
+
+// mask returns the placeholder for value, or "" for an empty value.
+func (r *redactor) mask(prefix, value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(value))
+	return prefix + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// redact masks the account and bank numbers of the statements and of the
+// accounts their transfers went to, their aggregator identifiers, nickname
+// and vendor extension values, and the FITIDs of their transactions, keeping
+// dates and amounts intact.
+func (r *redactor) redact(statements []*ofx.Ofx) {
+	for _, s := range statements {
+		s.AccountNumber = r.mask("ACCT-", s.AccountNumber)
+		s.AccountBankNumber = r.mask("BANK-", s.AccountBankNumber)
+		s.AggregatorBankID = r.mask("AGGBANK-", s.AggregatorBankID)
+		s.AggregatorUserID = r.mask("AGGUSER-", s.AggregatorUserID)
+		s.AggregatorAccountID = r.mask("AGGACCT-", s.AggregatorAccountID)
+		s.AccountNickname = r.mask("NICK-", s.AccountNickname)
+		for k, v := range s.Extensions {
+			s.Extensions[k] = r.mask("EXT-", v)
+		}
+		for _, t := range s.Transactions {
+			r.redactTransaction(t)
+		}
+		for _, t := range s.PendingTransactions {
+			r.redactTransaction(t)
+		}
+		for _, t := range s.InvestmentTransactions {
+			t.FitID = r.mask("FITID-", t.FitID)
+		}
+	}
+}
+
+// redactTransaction masks the FITID of t and the account of its transfer.
+func (r *redactor) redactTransaction(t *ofx.OfxTransaction) {
+	t.FitID = r.mask("FITID-", t.FitID)
+	if a := t.DestinationAccount; a != nil {
+		a.AccountID = r.mask("ACCT-", a.AccountID)
+		a.BankID = r.mask("BANK-", a.BankID)
+	}
+}
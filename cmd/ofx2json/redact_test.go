@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunRedact(t *testing.T) {
+	const name = "../../ofx/testdata/duplicates.ofx"
+
+	_, stdout, _ := runCLI(t, "", name)
+	plain := decodeStatement(t, stdout)
+
+	code, stdout, stderr := runCLI(t, "", "-redact", name)
+	if code != exitOK {
+		t.Fatalf("Wrong exit code. Expected: %d Actual: %d (%s)\n", exitOK, code, stderr)
+	}
+	if strings.Contains(stdout, "4444") || strings.Contains(stdout, "011000015") {
+		t.Errorf("Expected the account and bank numbers to be masked.\n%s\n", stdout)
+	}
+
+	redacted := decodeStatement(t, stdout)
+	if !strings.HasPrefix(redacted.AccountNumber, "ACCT-") || !strings.HasPrefix(redacted.AccountBankNumber, "BANK-") {
+		t.Errorf("Wrong placeholders. Expected: ACCT-... BANK-... Actual: %s %s\n", redacted.AccountNumber, redacted.AccountBankNumber)
+	}
+
+	// Repeated FITIDs keep their repeats; D1 is the first and last.
+	trans := redacted.Transactions
+	if trans[0].FitID == "D1" || trans[0].FitID != trans[4].FitID || trans[1].FitID != trans[2].FitID || trans[0].FitID == trans[1].FitID {
+		t.Errorf("Wrong masked FITIDs. Actual: %s %s %s %s %s\n", trans[0].FitID, trans[1].FitID, trans[2].FitID, trans[3].FitID, trans[4].FitID)
+	}
+	for i, trans := range redacted.Transactions {
+		if expected := plain.Transactions[i]; trans.Amount != expected.Amount || !trans.PostedDateTime.Equal(expected.PostedDateTime) {
+			t.Errorf("Wrong transaction. Expected: %s %s Actual: %s %s\n", expected.Amount, expected.PostedDateTime, trans.Amount, trans.PostedDateTime)
+		}
+	}
+}
+
+func TestRunRedactAggregator(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "-redact", "../../ofx/testdata/mint.ofx")
+	if code != exitOK {
+		t.Fatalf("Wrong exit code. Expected: %d Actual: %d (%s)\n", exitOK, code, stderr)
+	}
+	for _, value := range []string{"2398412", "mint-4f2a91", "b81c07de", "Joint Checking"} {
+		if strings.Contains(stdout, value) {
+			t.Errorf("Expected %s to be masked.\n%s\n", value, stdout)
+		}
+	}
+
+	redacted := decodeStatement(t, stdout)
+	if !strings.HasPrefix(redacted.AggregatorAccountID, "AGGACCT-") || !strings.HasPrefix(redacted.AggregatorUserID, "AGGUSER-") {
+		t.Errorf("Wrong placeholders. Expected: AGGACCT-... AGGUSER-... Actual: %s %s\n", redacted.AggregatorAccountID, redacted.AggregatorUserID)
+	}
+	if v := redacted.Extensions["INTU.SESSCOOKIE"]; !strings.HasPrefix(v, "EXT-") {
+		t.Errorf("Wrong INTU.SESSCOOKIE. Expected: EXT-... Actual: %s\n", v)
+	}
+}
+
+func TestRunRedactWarnings(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "-redact", "../../ofx/testdata/repeated.ofx")
+	if code != exitOK {
+		t.Fatalf("Wrong exit code. Expected: %d Actual: %d (%s)\n", exitOK, code, stderr)
+	}
+	if strings.Contains(stdout, "7777") || !strings.Contains(stdout, "Repeated statement") {
+		t.Errorf("Expected the repeated statement warning without the account number.\n%s\n", stdout)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/daniellawrence/ofx2json/ofx"
+)
+
+// transactionField is a transaction field that can be named in -select. The
+// name is also its CSV column header.
+type transactionField struct {
+	name    string
+	jsonKey string
+	value   func(t *ofx.OfxTransaction) string
+}
+
+var transactionFields = []transactionField{
+	{"date", "posted_datetime", func(t *ofx.OfxTransaction) string { return t.PostedDateTime.Format("2006-01-02") }},
+	{"user_date", "user_datetime", func(t *ofx.OfxTransaction) string {
+		if t.UserDateTime.IsZero() {
+			return ""
+		}
+		return t.UserDateTime.Format("2006-01-02")
+	}},
+	{"fitid", "fit_id", func(t *ofx.OfxTransaction) string { return t.FitID }},
+	{"type", "type", func(t *ofx.OfxTransaction) string { return t.Type }},
+	{"amount", "amount", func(t *ofx.OfxTransaction) string { return t.Amount.String() }},
+	{"currency", "currency", func(t *ofx.OfxTransaction) string { return t.Currency }},
+	{"currency_rate", "currency_rate", func(t *ofx.OfxTransaction) string {
+		if t.CurrencyRate == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(t.CurrencyRate, 'f', -1, 64)
+	}},
+	{"checknum", "check_num", func(t *ofx.OfxTransaction) string { return t.CheckNum }},
+	{"name", "name", func(t *ofx.OfxTransaction) string { return t.Name }},
+	{"memo", "memo", func(t *ofx.OfxTransaction) string { return t.Memo }},
+}
+
+// selectFields returns the transaction fields named in the comma separated
+// list, in the order given.
+func selectFields(list string) ([]transactionField, error) {
+	var fields []transactionField
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, f := range transactionFields {
+			if f.name == name {
+				fields = append(fields, f)
+				found = true
+				break
+			}
+		}
+		if !found {
+			var valid []string
+			for _, f := range transactionFields {
+				valid = append(valid, f.name)
+			}
+			return nil, fmt.Errorf("Unknown field: '%s', valid fields are: %s", name, strings.Join(valid, ", "))
+		}
+	}
+	return fields, nil
+}
+
+// projectJSON reduces every transaction in the marshalled statement or array
+// of statements b to the given fields, in the order given. Everything else
+// is kept as it was.
+func projectJSON(b []byte, fields []transactionField) ([]byte, error) {
+	if bytes.HasPrefix(b, []byte("[")) {
+		var statements []json.RawMessage
+		if err := json.Unmarshal(b, &statements); err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, s := range statements {
+			p, err := projectStatement(s, fields)
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(p)
+		}
+		buf.WriteByte(']')
+		return buf.Bytes(), nil
+	}
+	return projectStatement(b, fields)
+}
+
+func projectStatement(b []byte, fields []transactionField) ([]byte, error) {
+	// Walk the object with a decoder so that its keys keep their order.
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := tok.(string)
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		if key == "transactions" {
+			if value, err = projectTransactions(value, fields); err != nil {
+				return nil, err
+			}
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func projectTransactions(b []byte, fields []transactionField) ([]byte, error) {
+	var transactions []json.RawMessage
+	if err := json.Unmarshal(b, &transactions); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, t := range transactions {
+		p, err := projectTransaction(t, fields)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(p)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// projectTransaction reduces the marshalled transaction b to the given
+// fields, in the order given.
+func projectTransaction(b []byte, fields []transactionField) ([]byte, error) {
+	var t map[string]json.RawMessage
+	if err := json.Unmarshal(b, &t); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(strconv.Quote(f.jsonKey))
+		buf.WriteByte(':')
+		buf.Write(t[f.jsonKey])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunSelectJSON(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "-select", "date,amount,memo", fixture)
+	if code != 0 {
+		t.Fatalf("Wrong exit code. Expected: 0 Actual: %d (%s)\n", code, stderr)
+	}
+
+	var statement struct {
+		AccountNumber string                       `json:"account_number"`
+		Transactions  []map[string]json.RawMessage `json:"transactions"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &statement); err != nil {
+		t.Fatalf("Invalid json output: %v\n%s\n", err, stdout)
+	}
+
+	if statement.AccountNumber != "098-121" {
+		t.Errorf("Wrong account number. Expected: 098-121 Actual: %s\n", statement.AccountNumber)
+	}
+	if len(statement.Transactions) != 3 {
+		t.Fatalf("Wrong number of transactions. Expected: 3 Actual: %d\n", len(statement.Transactions))
+	}
+	for _, trans := range statement.Transactions {
+		var keys []string
+		for k := range trans {
+			keys = append(keys, k)
+		}
+		if len(keys) != 3 || trans["posted_datetime"] == nil || trans["amount"] == nil || trans["memo"] == nil {
+			t.Errorf("Wrong transaction keys. Expected: [amount memo posted_datetime] Actual: %v\n", keys)
+		}
+	}
+
+	if !strings.Contains(stdout, `{"posted_datetime":"2007-03-15T00:00:00Z","amount":200.00,"memo":`) {
+		t.Errorf("Transaction fields are not in the selected order.\n%s\n", stdout)
+	}
+}
+
+func TestRunSelectCSV(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "-format", "csv", "-select", "amount,date", fixture)
+	if code != 0 {
+		t.Fatalf("Wrong exit code. Expected: 0 Actual: %d (%s)\n", code, stderr)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(stdout)).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid csv output: %v\n%s\n", err, stdout)
+	}
+	expected := [][]string{
+		{"amount", "date"},
+		{"200.00", "2007-03-15"},
+		{"150.00", "2007-03-29"},
+	}
+	if len(rows) != 4 || !reflect.DeepEqual(rows[:3], expected) {
+		t.Errorf("Wrong csv rows.\nExpected: %q\nActual:   %q\n", expected, rows)
+	}
+}
+
+func TestRunSelectUnknownField(t *testing.T) {
+	code, _, stderr := runCLI(t, "", "-select", "date,balance", fixture)
+	if code != 2 {
+		t.Errorf("Wrong exit code. Expected: 2 Actual: %d\n", code)
+	}
+	for _, s := range []string{"'balance'", "date", "amount", "memo"} {
+		if !strings.Contains(stderr, s) {
+			t.Errorf("Error does not mention %s: %s\n", s, stderr)
+		}
+	}
+}
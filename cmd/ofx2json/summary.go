@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/daniellawrence/ofx2json/ofx"
+)
+
+// runSummary writes the summary of each statement, like parse -summary.
+func runSummary(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("ofx2json summary", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	in := addInputFlags(flags)
+	pretty := flags.Bool("pretty", false, "indent the JSON output")
+	indentFlag := flags.String("indent", "", "indent the JSON output with this `indent`: a number of spaces, or \\t for a tab; implies -pretty (default two spaces)")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	indent, err := parseIndent(*indentFlag, *pretty)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	statements, code := in.read(flags, stdin, stderr)
+	if code != exitOK {
+		return code
+	}
+	if err := writeSummary(stdout, statements, indent); err != nil {
+		fmt.Fprintf(stderr, "Failed to write summary, error: %v\n", err)
+		return exitUsage
+	}
+	return outcome(stderr, statements, false)
+}
+
+// summary is the -summary output for one statement.
+type summary struct {
+	AccountBankNumber string                  `json:"account_bank_number"`
+	AccountNumber     string                  `json:"account_number"`
+	AccountType       string                  `json:"account_type"`
+	Currency          string                  `json:"currency"`
+	Transactions      int                     `json:"transactions"`
+	Credits           ofx.Decimal             `json:"credits"`
+	Debits            ofx.Decimal             `json:"debits"`
+	Net               ofx.Decimal             `json:"net"`
+	Earliest          string                  `json:"earliest,omitempty"`
+	Latest            string                  `json:"latest,omitempty"`
+	Types             map[string]*typeSummary `json:"types"`
+}
+
+// typeSummary counts and totals the transactions of one TRNTYPE.
+type typeSummary struct {
+	Transactions int         `json:"transactions"`
+	Total        ofx.Decimal `json:"total"`
+}
+
+// summarize totals the transactions of a statement. Credits are the sum of
+// the positive amounts and debits of the negative ones, so debits is never
+// positive and net is their sum. Earliest and latest are the posting dates,
+// as YYYY-MM-DD, of the first and last transaction.
+func summarize(s *ofx.Ofx) summary {
+	sum := summary{
+		AccountBankNumber: s.AccountBankNumber,
+		AccountNumber:     s.AccountNumber,
+		AccountType:       s.AccountType,
+		Currency:          s.Currency,
+		Transactions:      len(s.Transactions),
+		Types:             map[string]*typeSummary{},
+	}
+
+	for i, t := range s.Transactions {
+		if t.Amount.Sign() > 0 {
+			sum.Credits = sum.Credits.Add(t.Amount)
+		} else {
+			sum.Debits = sum.Debits.Add(t.Amount)
+		}
+		sum.Net = sum.Net.Add(t.Amount)
+
+		ts := sum.Types[t.Type]
+		if ts == nil {
+			ts = &typeSummary{}
+			sum.Types[t.Type] = ts
+		}
+		ts.Transactions++
+		ts.Total = ts.Total.Add(t.Amount)
+
+		day := t.PostedDateTime.Format(dateLayout)
+		if i == 0 || day < sum.Earliest {
+			sum.Earliest = day
+		}
+		if day > sum.Latest {
+			sum.Latest = day
+		}
+	}
+	return sum
+}
+
+// writeSummary writes the summary of each statement as JSON: an object for
+// a single statement and an array for several, indented with indent unless
+// it is empty.
+func writeSummary(w io.Writer, statements []*ofx.Ofx, indent string) error {
+	summaries := []summary{}
+	for _, s := range statements {
+		summaries = append(summaries, summarize(s))
+	}
+
+	var o interface{} = summaries
+	if len(summaries) == 1 {
+		o = summaries[0]
+	}
+
+	res, err := json.Marshal(o)
+	if err == nil && indent != "" {
+		var buf bytes.Buffer
+		err = json.Indent(&buf, res, "", indent)
+		res = buf.Bytes()
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(res, '\n'))
+	return err
+}
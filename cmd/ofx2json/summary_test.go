@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRunSummary(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "-summary", "../../ofx/testdata/shuffled.ofx")
+	if code != exitOK {
+		t.Fatalf("Wrong exit code. Expected: %d Actual: %d (%s)\n", exitOK, code, stderr)
+	}
+
+	var sum summary
+	if err := json.Unmarshal([]byte(stdout), &sum); err != nil {
+		t.Fatalf("Invalid summary: %v\n%s\n", err, stdout)
+	}
+
+	if sum.AccountNumber != "098-121" || sum.Transactions != 5 {
+		t.Errorf("Wrong account or count. Expected: 098-121 5 Actual: %s %d\n", sum.AccountNumber, sum.Transactions)
+	}
+	for _, test := range []struct{ name, expected, actual string }{
+		{"credits", "500.00", sum.Credits.String()},
+		{"debits", "-330.00", sum.Debits.String()},
+		{"net", "170.00", sum.Net.String()},
+		{"earliest", "2007-03-01", sum.Earliest},
+		{"latest", "2007-03-20", sum.Latest},
+		{"CREDIT total", "500.00", sum.Types["CREDIT"].Total.String()},
+		{"DEBIT total", "-330.00", sum.Types["DEBIT"].Total.String()},
+	} {
+		if test.actual != test.expected {
+			t.Errorf("Wrong %s. Expected: %s Actual: %s\n", test.name, test.expected, test.actual)
+		}
+	}
+	if n := sum.Types["DEBIT"].Transactions; n != 4 {
+		t.Errorf("Wrong number of DEBIT transactions. Expected: 4 Actual: %d\n", n)
+	}
+}
+
+func TestRunSummaryFormat(t *testing.T) {
+	code, _, stderr := runCLI(t, "", "-summary", "-format", "csv", fixture)
+	if code != exitUsage || !strings.Contains(stderr, "-summary") {
+		t.Errorf("Expected exit code %d and a usage hint. Actual: %d %s\n", exitUsage, code, stderr)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/daniellawrence/ofx2json/ofx"
+)
+
+// transactionData is what a -template is executed with for each
+// transaction: the fields of the transaction, e.g. {{.Amount}} or
+// {{.PostedDateTime.Format "2006-01-02"}}, and those of its statement as
+// {{.Statement.AccountNumber}}.
+type transactionData struct {
+	*ofx.OfxTransaction
+	Statement *ofx.Ofx
+}
+
+// parseTemplate parses the text of -template.
+func parseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("template").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// writeTemplate executes tmpl once per transaction or, when scope is
+// "statement", once per statement with the *ofx.Ofx as dot. Each rendering
+// is followed by a newline unless it already ends with one.
+func writeTemplate(w io.Writer, tmpl *template.Template, scope string, statements []*ofx.Ofx) error {
+	bw := bufio.NewWriter(w)
+	var buf bytes.Buffer
+	render := func(data interface{}) error {
+		buf.Reset()
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return err
+		}
+		if b := buf.Bytes(); len(b) == 0 || b[len(b)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+		_, err := bw.Write(buf.Bytes())
+		return err
+	}
+
+	for _, s := range statements {
+		if scope == "statement" {
+			if err := render(s); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, t := range s.Transactions {
+			if err := render(transactionData{OfxTransaction: t, Statement: s}); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
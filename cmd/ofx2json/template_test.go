@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunTemplate(t *testing.T) {
+	const name = "../../ofx/testdata/quoting.ofx"
+
+	code, stdout, stderr := runCLI(t, "", "-template", `{{.Statement.AccountNumber}} {{.PostedDateTime.Format "2006-01-02"}} {{.Amount}} {{.Name}}`, name)
+	if code != exitOK {
+		t.Fatalf("Wrong exit code. Expected: %d Actual: %d (%s)\n", exitOK, code, stderr)
+	}
+	expected := "9999 2019-01-05 -61.20 JOE'S DINER, INC\n" +
+		"9999 2019-01-09 -100.00 RENT\n" +
+		"9999 2019-01-15 2500.00 PAYROLL\n"
+	if stdout != expected {
+		t.Errorf("Wrong output.\nExpected: %q\nActual:   %q\n", expected, stdout)
+	}
+
+	_, stdout, _ = runCLI(t, "", "-template-scope", "statement", "-template", "{{.AccountNumber}}:{{range .Transactions}} {{.FitID}}{{end}}", name)
+	if expected := "9999: Q1 Q2 Q3\n"; stdout != expected {
+		t.Errorf("Wrong statement output. Expected: %q Actual: %q\n", expected, stdout)
+	}
+}
+
+func TestRunTemplateInvalid(t *testing.T) {
+	for _, args := range [][]string{
+		{"-template", "{{.Amount"},
+		{"-template", "{{.Amount}}", "-format", "csv"},
+		{"-template", "{{.Amount}}", "-template-scope", "account"},
+	} {
+		code, _, stderr := runCLI(t, "", append(args, fixture)...)
+		if code != exitUsage || stderr == "" {
+			t.Errorf("Expected exit code %d and an error for %v. Actual: %d %s\n", exitUsage, args, code, stderr)
+		}
+	}
+
+	code, _, stderr := runCLI(t, "", "-template", "{{.NoSuchField}}", fixture)
+	if code != exitUsage || !strings.Contains(stderr, "NoSuchField") {
+		t.Errorf("Expected exit code %d and the unknown field. Actual: %d %s\n", exitUsage, code, stderr)
+	}
+}
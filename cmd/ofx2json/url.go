@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/daniellawrence/ofx2json/ofx"
+)
+
+// headerFlags collects the repeatable -header flag.
+type headerFlags []string
+
+func (h *headerFlags) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headerFlags) Set(value string) error {
+	if i := strings.IndexByte(value, ':'); i <= 0 {
+		return fmt.Errorf("Invalid header '%s', expected 'Name: value'", value)
+	}
+	*h = append(*h, value)
+	return nil
+}
+
+// fetchURL parses the OFX document served at url, sending each of the
+// "Name: value" headers with the GET request. Responses other than 2xx are
+// errors.
+func fetchURL(url string, headers []string, timeout time.Duration, opts []ofx.Option) (*ofx.OfxDocument, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid url '%s', error: %w", url, err)
+	}
+	for _, h := range headers {
+		i := strings.IndexByte(h, ':')
+		req.Header.Add(strings.TrimSpace(h[:i]), strings.TrimSpace(h[i+1:]))
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch %s, error: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("Failed to fetch %s, status: %s", url, resp.Status)
+	}
+
+	doc, err := ofx.ParseDocument(resp.Body, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse %s, error: %w", url, err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunURL(t *testing.T) {
+	bts, err := ioutil.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("X-Client") != "ofx2json":
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ofx")
+		w.Write(bts)
+	}))
+	defer server.Close()
+
+	code, stdout, stderr := runCLI(t, "", "-url", server.URL+"/statement.ofx",
+		"-header", "Authorization: Bearer secret", "-header", "X-Client: ofx2json")
+	if code != exitOK {
+		t.Fatalf("Wrong exit code. Expected: %d Actual: %d (%s)\n", exitOK, code, stderr)
+	}
+	if actual := decodeStatement(t, stdout).AccountNumber; actual != "098-121" {
+		t.Errorf("Wrong account number. Expected: %s Actual: %s\n", "098-121", actual)
+	}
+
+	code, _, stderr = runCLI(t, "", "-url", server.URL+"/statement.ofx")
+	if code != exitInput || !strings.Contains(stderr, "401") {
+		t.Errorf("Expected exit code %d and the status. Actual: %d %s\n", exitInput, code, stderr)
+	}
+
+	code, _, stderr = runCLI(t, "", "-url", server.URL+"/slow", "-timeout", "50ms")
+	if code != exitInput || !strings.Contains(stderr, "Failed to fetch") {
+		t.Errorf("Expected exit code %d on timeout. Actual: %d %s\n", exitInput, code, stderr)
+	}
+}
+
+func TestRunURLUsage(t *testing.T) {
+	tests := [][]string{
+		{"-url", "http://localhost/statement.ofx", fixture},
+		{"-url", "http://localhost/statement.ofx", "-header", "no colon"},
+	}
+	for _, args := range tests {
+		if code, _, _ := runCLI(t, "", args...); code != exitUsage {
+			t.Errorf("Wrong exit code for %v. Expected: %d Actual: %d\n", args, exitUsage, code)
+		}
+	}
+}
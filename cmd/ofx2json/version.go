@@ -0,0 +1,36 @@
+package main
+
+import (
+	"runtime/debug"
+)
+
+// version may be set at link time with -ldflags "-X main.version=v1.2.3";
+// otherwise it is taken from the module build info.
+var version = ""
+
+// buildVersion returns the version of this build: the module version for
+// binaries installed with go install, or "(devel)" for local builds, which
+// are followed by the VCS revision when it is known.
+func buildVersion() string {
+	if version != "" {
+		return version
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+
+	v := info.Main.Version
+	if v == "" {
+		v = "(devel)"
+	}
+	if v == "(devel)" {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" && len(s.Value) >= 12 {
+				v += " " + s.Value[:12]
+			}
+		}
+	}
+	return v
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRunVersion(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "-version")
+	if code != 0 {
+		t.Fatalf("Wrong exit code. Expected: 0 Actual: %d (%s)\n", code, stderr)
+	}
+
+	versionLine := regexp.MustCompile(`^ofx2json (v\d+\.\d+\.\d+\S*|\(devel\)( [0-9a-f]{12})?)\n$`)
+	if !versionLine.MatchString(stdout) {
+		t.Errorf("Wrong version output. Actual: %q\n", stdout)
+	}
+}
+
+func TestBuildVersionOverride(t *testing.T) {
+	defer func(v string) { version = v }(version)
+	version = "v1.2.3"
+
+	if actual := buildVersion(); actual != "v1.2.3" {
+		t.Errorf("Wrong version. Expected: v1.2.3 Actual: %s\n", actual)
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/daniellawrence/ofx2json
 
-go 1.17
+go 1.23
+
+require golang.org/x/text v0.13.0
@@ -0,0 +1,24 @@
+package ofx
+
+import "sort"
+
+// ComputeRunningBalances sets the RunningBalance of each transaction to the
+// balance of the account once it was posted, starting from the balance
+// start before the first. Transactions are walked oldest first, in the
+// order of SortByDate, but are left in the order of the statement.
+//
+// Starting from the balance before the statement period, the last running
+// balance is the ledger balance.
+func (o *Ofx) ComputeRunningBalances(start Decimal) {
+	ordered := append([]*OfxTransaction(nil), o.Transactions...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return postedBefore(ordered[i], ordered[j])
+	})
+
+	balance := start
+	for _, trans := range ordered {
+		balance = balance.Add(trans.Amount)
+		running := balance
+		trans.RunningBalance = &running
+	}
+}
@@ -0,0 +1,30 @@
+package ofx
+
+import "testing"
+
+func TestComputeRunningBalances(t *testing.T) {
+	_ofx := parseFile(t, "testdata/shuffled.ofx")
+	_ofx.ComputeRunningBalances(NewDecial("0.00"))
+
+	expected := map[string]string{
+		"S0": "-250.00",
+		"S1": "250.00",
+		"S3": "238.00",
+		"S4": "230.00",
+		"S5": "170.00",
+	}
+	for _, trans := range _ofx.Transactions {
+		if actual := trans.RunningBalance.String(); actual != expected[trans.FitID] {
+			t.Errorf("Wrong running balance for %s. Expected: %s Actual: %s\n", trans.FitID, expected[trans.FitID], actual)
+		}
+	}
+
+	if first := _ofx.Transactions[0].FitID; first != "S3" {
+		t.Errorf("Expected the transactions to keep their order. Actual first: %s\n", first)
+	}
+
+	last := _ofx.Transactions[2].RunningBalance
+	if last.cmp(_ofx.LedgerBalance) != 0 {
+		t.Errorf("Wrong final running balance. Expected: %s Actual: %s\n", _ofx.LedgerBalance, last)
+	}
+}
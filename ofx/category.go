@@ -0,0 +1,54 @@
+package ofx
+
+import (
+	"sort"
+	"strings"
+)
+
+// Categorizer returns the category of a transaction, such as "Groceries",
+// or "" to leave it uncategorized. Parsing WithCategorizer stores it in
+// OfxTransaction.Category.
+type Categorizer func(*OfxTransaction) string
+
+// NoCategory is the default Categorizer, leaving every transaction
+// uncategorized.
+func NoCategory(*OfxTransaction) string {
+	return ""
+}
+
+// KeywordCategorizer returns a simple Categorizer that maps keywords to
+// categories, e.g. "GROCER" to "Groceries". A transaction gets the category
+// of a keyword found, ignoring case, in its name, payee name or memo. Longer
+// keywords are tried first, so "COFFEE BEANS" can be told apart from
+// "COFFEE", and keywords of the same length in alphabetical order.
+func KeywordCategorizer(keywords map[string]string) Categorizer {
+	keys := make([]string, 0, len(keywords))
+	for k := range keywords {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	upper := make([]string, len(keys))
+	for i, k := range keys {
+		upper[i] = strings.ToUpper(k)
+	}
+
+	return func(t *OfxTransaction) string {
+		text := t.Name + "\n" + t.Memo
+		if t.Payee != nil {
+			text += "\n" + t.Payee.Name
+		}
+		text = strings.ToUpper(text)
+		for i, k := range upper {
+			if k != "" && strings.Contains(text, k) {
+				return keywords[keys[i]]
+			}
+		}
+		return ""
+	}
+}
@@ -0,0 +1,85 @@
+package ofx
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// headerEncoding returns the character encoding declared by h, or nil when
+// the body is UTF-8 (or plain ASCII) and needs no transcoding.
+//
+// OFX 1.x headers declare ENCODING:USASCII together with a CHARSET such as
+// 1252 or ISO-8859-1, or ENCODING:UNICODE for UTF-8. OFX 2.x files carry the
+// encoding attribute of their <?xml?> declaration.
+func headerEncoding(h Header) (encoding.Encoding, error) {
+	name := strings.ToUpper(strings.TrimSpace(h.Encoding))
+	switch name {
+	case "", "UTF-8", "UTF8", "UNICODE":
+		return nil, nil
+	case "USASCII", "US-ASCII":
+		name = strings.ToUpper(strings.TrimSpace(h.Charset))
+		switch {
+		case name == "" || name == "NONE":
+			return nil, nil
+		case isDigits(name):
+			// Numeric charsets name Windows code pages.
+			name = "WINDOWS-" + name
+		case strings.HasPrefix(name, "8859-"):
+			name = "ISO-" + name
+		}
+	}
+
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("Unsupported OFX character set: '%s'", name)
+	}
+	if enc == unicode.UTF8 {
+		return nil, nil
+	}
+	return enc, nil
+}
+
+// decodeBody returns a reader that transcodes r from the encoding declared by
+// h to UTF-8.
+func decodeBody(r io.Reader, h Header) (io.Reader, error) {
+	enc, err := headerEncoding(h)
+	if err != nil || enc == nil {
+		return r, err
+	}
+	return transform.NewReader(r, enc.NewDecoder()), nil
+}
+
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// stripBOM consumes a byte order mark at the start of r. A UTF-8 one is
+// dropped. A UTF-16 one, in either byte order, means the whole document is
+// UTF-16, whatever its header declares, so the returned reader transcodes it
+// to UTF-8 and transcoded is set.
+func stripBOM(r *bufio.Reader) (_ *bufio.Reader, transcoded bool, err error) {
+	b, err := r.Peek(len(utf8BOM))
+	if err != nil && err != io.EOF {
+		return nil, false, err
+	}
+
+	var enc encoding.Encoding
+	switch {
+	case bytes.Equal(b, utf8BOM):
+		_, err := r.Discard(len(utf8BOM))
+		return r, false, err
+	case bytes.HasPrefix(b, []byte{0xff, 0xfe}):
+		enc = unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+	case bytes.HasPrefix(b, []byte{0xfe, 0xff}):
+		enc = unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+	default:
+		return r, false, nil
+	}
+	return bufio.NewReader(transform.NewReader(r, enc.NewDecoder())), true, nil
+}
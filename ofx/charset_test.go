@@ -0,0 +1,108 @@
+package ofx
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestParseWindows1252(t *testing.T) {
+	_ofx := parseFile(t, "testdata/windows1252.ofx")
+
+	if len(_ofx.Transactions) != 2 {
+		t.Fatalf("Wrong number of transactions. Expected: 2 Actual: %d\n", len(_ofx.Transactions))
+	}
+
+	expected := []struct{ name, memo string }{
+		{"Café Müller", "Loyer – août"},
+		{"Épicerie Française", ""},
+	}
+	for i, e := range expected {
+		trans := _ofx.Transactions[i]
+		if trans.Name != e.name {
+			t.Errorf("Wrong name. Expected: %s Actual: %s\n", e.name, trans.Name)
+		}
+		if trans.Memo != e.memo {
+			t.Errorf("Wrong memo. Expected: %s Actual: %s\n", e.memo, trans.Memo)
+		}
+	}
+}
+
+func TestHeaderEncoding(t *testing.T) {
+	tests := []struct {
+		header   Header
+		expected string
+	}{
+		{Header{}, ""},
+		{Header{Encoding: "UTF-8"}, ""},
+		{Header{Encoding: "UNICODE", Charset: "NONE"}, ""},
+		{Header{Encoding: "USASCII", Charset: "NONE"}, ""},
+		{Header{Encoding: "USASCII", Charset: "1252"}, "Windows 1252"},
+		{Header{Encoding: "USASCII", Charset: "8859-1"}, "Windows 1252"},
+		{Header{Encoding: "USASCII", Charset: "1250"}, "Windows 1250"},
+		{Header{Encoding: "windows-1252"}, "Windows 1252"},
+	}
+	for _, test := range tests {
+		enc, err := headerEncoding(test.header)
+		if err != nil {
+			t.Errorf("Unexpected error for %+v: %s\n", test.header, err)
+			continue
+		}
+		actual := ""
+		if enc != nil {
+			actual = enc.(interface{ String() string }).String()
+		}
+		if actual != test.expected {
+			t.Errorf("Wrong encoding for %+v. Expected: %s Actual: %s\n", test.header, test.expected, actual)
+		}
+	}
+
+	if _, err := headerEncoding(Header{Encoding: "USASCII", Charset: "BOGUS"}); err == nil {
+		t.Errorf("Expected an error for an unknown character set\n")
+	}
+}
+
+func TestParseBOM(t *testing.T) {
+	expected := parseFile(t, "testdata/v103.ofx")
+	for _, name := range []string{"testdata/bom-utf8.ofx", "testdata/bom-utf16le.ofx"} {
+		if actual := parseFile(t, name); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Wrong statement for %s. Expected: %s Actual: %s\n", name, expected, actual)
+		}
+	}
+
+	// A UTF-16 document may also declare itself so, which is not decoded
+	// again.
+	b, err := ioutil.ReadFile("testdata/pending.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	xml := strings.Replace(string(b), `encoding="UTF-8"`, `encoding="UTF-16"`, 1)
+	utf16, err := unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewEncoder().String(xml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(utf16, "\xfe\xff") {
+		t.Fatalf("Expected a big endian byte order mark. Actual: %q\n", utf16[:2])
+	}
+	actual, err := Parse(strings.NewReader(utf16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual.Header.Encoding != "UTF-16" {
+		t.Errorf("Wrong encoding. Expected: UTF-16 Actual: %s\n", actual.Header.Encoding)
+	}
+	actual.Header = Header{}
+	pending := parseFile(t, "testdata/pending.xml")
+	pending.Header = Header{}
+	if !reflect.DeepEqual(actual, pending) {
+		t.Errorf("Wrong UTF-16 statement. Expected: %s Actual: %s\n", pending, actual)
+	}
+
+	if _, err := Parse(bytes.NewReader(utf8BOM)); err == nil {
+		t.Errorf("Expected an error for a lone byte order mark\n")
+	}
+}
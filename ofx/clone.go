@@ -0,0 +1,63 @@
+package ofx
+
+// Clone returns a deep copy of o: its transactions, balances, securities,
+// extensions and warnings are copied too, so that the copy can be filtered
+// or modified, e.g. with NormalizeSigns, without changing o. Nil and empty
+// slices and maps stay so in the copy.
+func (o *Ofx) Clone() *Ofx {
+	c := *o
+	c.Transactions = cloneTransactions(o.Transactions)
+	c.PendingTransactions = cloneTransactions(o.PendingTransactions)
+	if o.InvestmentTransactions != nil {
+		c.InvestmentTransactions = make([]*InvestmentTransaction, len(o.InvestmentTransactions))
+		for i, t := range o.InvestmentTransactions {
+			copied := *t
+			c.InvestmentTransactions[i] = &copied
+		}
+	}
+	if o.Balances != nil {
+		c.Balances = append([]NamedBalance{}, o.Balances...)
+	}
+	if o.Securities != nil {
+		c.Securities = make(map[string]Security, len(o.Securities))
+		for id, s := range o.Securities {
+			c.Securities[id] = s
+		}
+	}
+	if o.Extensions != nil {
+		c.Extensions = make(map[string]string, len(o.Extensions))
+		for k, v := range o.Extensions {
+			c.Extensions[k] = v
+		}
+	}
+	if o.Warnings != nil {
+		c.Warnings = append([]string{}, o.Warnings...)
+	}
+	return &c
+}
+
+// cloneTransactions copies each transaction along with its payee,
+// destination account and running balance.
+func cloneTransactions(transactions []*OfxTransaction) []*OfxTransaction {
+	if transactions == nil {
+		return nil
+	}
+	c := make([]*OfxTransaction, len(transactions))
+	for i, t := range transactions {
+		copied := *t
+		if t.Payee != nil {
+			payee := *t.Payee
+			copied.Payee = &payee
+		}
+		if t.DestinationAccount != nil {
+			account := *t.DestinationAccount
+			copied.DestinationAccount = &account
+		}
+		if t.RunningBalance != nil {
+			balance := *t.RunningBalance
+			copied.RunningBalance = &balance
+		}
+		c[i] = &copied
+	}
+	return c
+}
@@ -0,0 +1,49 @@
+package ofx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	for _, name := range []string{"testdata/payee.ofx", "testdata/transfer.ofx", "testdata/seclist.ofx", "testdata/ballist.ofx", "testdata/pending.xml", "testdata/unknown.ofx"} {
+		original := parseFile(t, name)
+		expected := parseFile(t, name)
+
+		c := original.Clone()
+		if !reflect.DeepEqual(c, original) {
+			t.Errorf("Wrong clone of %s.\nExpected: %s\nActual:   %s\n", name, original, c)
+		}
+
+		c.AccountNumber = "cloned"
+		c.Transactions = c.Transactions[:1]
+		for _, trans := range append(c.Transactions, c.PendingTransactions...) {
+			trans.Amount = NewDecial("-1.00")
+			trans.Memo = "cloned"
+			if trans.Payee != nil {
+				trans.Payee.Name = "cloned"
+			}
+			if trans.DestinationAccount != nil {
+				trans.DestinationAccount.AccountID = "cloned"
+			}
+		}
+		for _, trans := range c.InvestmentTransactions {
+			trans.Units = -1
+		}
+		for i := range c.Balances {
+			c.Balances[i].Name = "cloned"
+		}
+		for id := range c.Securities {
+			c.Securities[id] = Security{}
+		}
+		c.NormalizeSigns()
+		c.Extensions = nil
+		if c.Warnings != nil {
+			c.Warnings[0] = "cloned"
+		}
+
+		if !reflect.DeepEqual(original, expected) {
+			t.Errorf("Expected %s to be unchanged by changes to its clone.\nExpected: %s\nActual:   %s\n", name, expected, original)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package ofx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// cancellingReader cancels its context once half of the input has been
+// read.
+type cancellingReader struct {
+	r      io.Reader
+	after  int
+	read   int
+	cancel context.CancelFunc
+}
+
+func (r *cancellingReader) Read(p []byte) (int, error) {
+	if len(p) > 64 {
+		p = p[:64]
+	}
+	n, err := r.r.Read(p)
+	if r.read += n; r.read >= r.after {
+		r.cancel()
+	}
+	return n, err
+}
+
+func TestParseContextCancelled(t *testing.T) {
+	bts, err := ioutil.ReadFile("testdata/v103.ofx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := &cancellingReader{r: bytes.NewReader(bts), after: len(bts) / 2, cancel: cancel}
+
+	_ofx, err := ParseContext(ctx, r)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled. Actual: %v %v\n", err, _ofx)
+	}
+}
+
+func TestParseContext(t *testing.T) {
+	f, err := os.Open("testdata/v103.ofx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_ofx, err := ParseContext(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifyOfx(t, _ofx, "098-121", "987654321")
+}
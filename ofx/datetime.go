@@ -0,0 +1,119 @@
+package ofx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// parseDateTime parses an OFX datetime value of the form
+// YYYYMMDD[HHMMSS[.XXX]][[gmt offset[:tz name]]], e.g.
+// 20231005143000.000[-5:EST]. Values without an offset are returned in UTC.
+func parseDateTime(s string) (time.Time, error) {
+	return parseDateTimeIn(s, time.UTC)
+}
+
+// parseDateTimeIn is like parseDateTime, but interprets values without an
+// offset in naive.
+func parseDateTimeIn(s string, naive *time.Location) (time.Time, error) {
+	value, zone := s, ""
+	if i := strings.IndexByte(s, '['); i >= 0 {
+		if !strings.HasSuffix(s, "]") {
+			return time.Time{}, fmt.Errorf("Invalid datetime string: '%s'", s)
+		}
+		value, zone = s[:i], s[i+1:len(s)-1]
+	}
+
+	var layout string
+	switch {
+	case len(value) == 8:
+		layout = "20060102"
+	case len(value) == 12:
+		layout = "200601021504"
+	case len(value) == 14:
+		layout = "20060102150405"
+	case len(value) > 15 && value[14] == '.':
+		layout = "20060102150405." + strings.Repeat("0", len(value)-15)
+	default:
+		return time.Time{}, fmt.Errorf("Invalid datetime string: '%s'", s)
+	}
+
+	loc := naive
+	if zone != "" {
+		var err error
+		if loc, err = parseZone(zone); err != nil {
+			return time.Time{}, fmt.Errorf("Invalid datetime string: '%s': %v", s, err)
+		}
+	}
+
+	t, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid datetime string: '%s': %v", s, err)
+	}
+	return t, nil
+}
+
+// parseZone converts the bracketed "offset[:name]" suffix of an OFX datetime,
+// where offset is a (possibly fractional) number of hours from GMT, into a
+// fixed location.
+func parseZone(zone string) (*time.Location, error) {
+	offset, name := zone, ""
+	if i := strings.IndexByte(zone, ':'); i >= 0 {
+		offset, name = zone[:i], zone[i+1:]
+	}
+
+	hours, err := strconv.ParseFloat(offset, 64)
+	if err != nil {
+		return nil, fmt.Errorf("bad gmt offset '%s'", offset)
+	}
+
+	if name == "" {
+		if hours == 0 {
+			return time.UTC, nil
+		}
+		name = "GMT" + offset
+	}
+	return fixedZone(name, int(hours*3600)), nil
+}
+
+// maxZones bounds the zone cache, as the zones come from untrusted input.
+const maxZones = 64
+
+var zones = struct {
+	sync.Mutex
+	m map[string]*time.Location
+}{m: map[string]*time.Location{}}
+
+// fixedZone is time.FixedZone, except that the same name and offset always
+// yield the same *time.Location. This keeps times parsed from the same zone
+// comparable with reflect.DeepEqual.
+func fixedZone(name string, offset int) *time.Location {
+	key := name + "/" + strconv.Itoa(offset)
+
+	zones.Lock()
+	defer zones.Unlock()
+
+	if loc, ok := zones.m[key]; ok {
+		return loc
+	}
+	loc := time.FixedZone(name, offset)
+	if len(zones.m) < maxZones {
+		zones.m[key] = loc
+	}
+	return loc
+}
+
+// formatDateTime formats t in the canonical OFX datetime form
+// YYYYMMDDHHMMSS.XXX, followed by the [offset:name] zone unless t is in UTC.
+func formatDateTime(t time.Time) string {
+	s := t.Format("20060102150405.000")
+	if t.Location() == time.UTC {
+		return s
+	}
+
+	name, offset := t.Zone()
+	hours := strconv.FormatFloat(float64(offset)/3600, 'f', -1, 64)
+	return s + "[" + hours + ":" + name + "]"
+}
@@ -0,0 +1,46 @@
+package ofx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDateTime(t *testing.T) {
+	est := time.FixedZone("EST", -5*3600)
+
+	tests := []struct {
+		in       string
+		expected time.Time
+	}{
+		{"20231005", time.Date(2023, 10, 5, 0, 0, 0, 0, time.UTC)},
+		{"20231005143000", time.Date(2023, 10, 5, 14, 30, 0, 0, time.UTC)},
+		{"20231005143000.123", time.Date(2023, 10, 5, 14, 30, 0, 123000000, time.UTC)},
+		{"20231005143000.000[-5:EST]", time.Date(2023, 10, 5, 14, 30, 0, 0, est)},
+		{"20231005143000[+9.5:ACST]", time.Date(2023, 10, 5, 14, 30, 0, 0, time.FixedZone("ACST", 34200))},
+	}
+
+	for _, test := range tests {
+		actual, err := parseDateTime(test.in)
+		if err != nil {
+			t.Errorf("Failed to parse %s: %v\n", test.in, err)
+			continue
+		}
+
+		if !actual.Equal(test.expected) {
+			t.Errorf("Wrong time for %s. Expected: %s Actual: %s\n", test.in, test.expected, actual)
+		}
+
+		_, expectedOffset := test.expected.Zone()
+		if name, offset := actual.Zone(); offset != expectedOffset {
+			t.Errorf("Wrong zone for %s. Expected: %d Actual: %s %d\n", test.in, expectedOffset, name, offset)
+		}
+	}
+}
+
+func TestParseDateTimeInvalid(t *testing.T) {
+	for _, in := range []string{"", "2023", "20231005143000[-5:EST", "20231005[abc]", "2023100514"} {
+		if _, err := parseDateTime(in); err == nil {
+			t.Errorf("Expected an error parsing '%s'\n", in)
+		}
+	}
+}
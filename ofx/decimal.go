@@ -0,0 +1,487 @@
+package ofx
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxPlaces bounds the number of decimal places a Decimal may carry, so that
+// the scaling factor always fits in an int64.
+const maxPlaces = 18
+
+// Decimal is a monetary amount stored as an integer number of minor units
+// of its currency (cents for most currencies) together with the number of
+// decimal places those units represent.
+//
+// Decimal marshals to JSON as a number in whole currency units, e.g. 12.34.
+type Decimal struct {
+	units  int64
+	places uint8
+}
+
+// DecimalFromUnits returns the amount of the given number of minor units,
+// each worth 10^-places of a whole currency unit.
+func DecimalFromUnits(units int64, places int) Decimal {
+	if places < 0 || places > maxPlaces {
+		panic(fmt.Sprintf("ofx: invalid number of decimal places %d", places))
+	}
+	return Decimal{units: units, places: uint8(places)}
+}
+
+// Places returns the number of decimal places of the amount.
+func (d Decimal) Places() int {
+	return int(d.places)
+}
+
+// Float64 returns the amount in whole currency units. Floats cannot hold
+// most decimal fractions exactly, so use it for display and Cents, Add and
+// Sub for arithmetic.
+func (d Decimal) Float64() float64 {
+	x := float64(d.units)
+	x = x / math.Pow10(int(d.places))
+	return x
+}
+
+// Cents returns the amount in hundredths of a currency unit. Amounts with
+// more than two decimal places lose the extra digits towards zero.
+func (d Decimal) Cents() int64 {
+	if d.places <= 2 {
+		c, ok := d.rescale(2)
+		if !ok {
+			panic(fmt.Sprintf("ofx: %s overflows in cents", d))
+		}
+		return c.units
+	}
+	return d.units / int64(math.Pow10(int(d.places)-2))
+}
+
+// String formats the amount with its number of decimal places and, for
+// negative amounts, a single leading minus sign.
+func (d Decimal) String() string {
+	sign := ""
+	abs := uint64(d.units)
+	if d.units < 0 {
+		sign = "-"
+		abs = -abs
+	}
+
+	s := strconv.FormatUint(abs, 10)
+	places := int(d.places)
+	if places == 0 {
+		return sign + s
+	}
+	if len(s) <= places {
+		s = strings.Repeat("0", places-len(s)+1) + s
+	}
+	return sign + s[:len(s)-places] + "." + s[len(s)-places:]
+}
+
+// rescale returns d with at least the given number of decimal places, and
+// false if its units then no longer fit in an int64.
+func (d Decimal) rescale(places uint8) (Decimal, bool) {
+	for d.places < places {
+		if d.units > math.MaxInt64/10 || d.units < math.MinInt64/10 {
+			return d, false
+		}
+		d.units *= 10
+		d.places++
+	}
+	return d, true
+}
+
+// Add returns d+e exactly, with the larger of their numbers of decimal
+// places. It panics if the result does not fit in a Decimal, which can only
+// happen for huge amounts or when mixing amounts with many decimal places,
+// as kept by KeepExcessPlaces.
+func (d Decimal) Add(e Decimal) Decimal {
+	rd, ok := d.rescale(e.places)
+	re, ok2 := e.rescale(d.places)
+	sum := rd.units + re.units
+	if !ok || !ok2 || (re.units > 0 && sum < rd.units) || (re.units < 0 && sum > rd.units) {
+		panic(fmt.Sprintf("ofx: %s + %s overflows a Decimal", d, e))
+	}
+	return Decimal{units: sum, places: rd.places}
+}
+
+// Sub returns d-e exactly, with the larger of their numbers of decimal
+// places. Like Add, it panics if the result does not fit in a Decimal.
+func (d Decimal) Sub(e Decimal) Decimal {
+	if e.units == math.MinInt64 {
+		panic(fmt.Sprintf("ofx: %s - %s overflows a Decimal", d, e))
+	}
+	e.units = -e.units
+	return d.Add(e)
+}
+
+// Sign returns -1, 0 or +1 as d is negative, zero or positive.
+func (d Decimal) Sign() int {
+	switch {
+	case d.units < 0:
+		return -1
+	case d.units > 0:
+		return 1
+	}
+	return 0
+}
+
+// cmp compares d and e numerically, returning -1, 0 or +1.
+func (d Decimal) cmp(e Decimal) int {
+	// Only the one with fewer places is rescaled; if it overflows, it is
+	// larger in magnitude than the other.
+	d, ok := d.rescale(e.places)
+	if !ok {
+		return d.Sign()
+	}
+	e, ok = e.rescale(d.places)
+	if !ok {
+		return -e.Sign()
+	}
+	switch {
+	case d.units < e.units:
+		return -1
+	case d.units > e.units:
+		return 1
+	}
+	return 0
+}
+
+// MarshalJSON encodes the amount as a JSON number in whole currency units,
+// with its number of decimal places, e.g. 12.34 or -0.50.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number, or a string holding one, keeping as
+// many decimal places as it was written with so that marshalled amounts
+// round-trip exactly. Exponents are not accepted.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+
+	v, err := parseDecimalExact(s)
+	if err != nil {
+		return fmt.Errorf("Invalid decimal json: '%s'", b)
+	}
+	*d = v
+	return nil
+}
+
+// SetString is the lenient form of ParseDecimal, returning zero when s is
+// not a valid number.
+func (d Decimal) SetString(s string) Decimal {
+	return NewDecial(s)
+}
+
+// NewDecial is the lenient form of ParseDecimal, returning zero when s is
+// not a valid number.
+func NewDecial(s string) Decimal {
+	d, _ := ParseDecimal(s)
+	return d
+}
+
+// ParseDecimal parses a decimal amount such as "-12.34" into cents. The
+// string is converted directly, without going through a float, so amounts
+// like "0.29" are exact. Digits beyond the second decimal place are
+// truncated.
+func ParseDecimal(s string) (Decimal, error) {
+	return ParseDecimalPlaces(s, 2)
+}
+
+// ParseDecimalPlaces is like ParseDecimal but keeps the given number of
+// decimal places, e.g. 0 for JPY or 3 for BHD. An optional leading '-' or
+// '+' gives the sign; truncated digits are dropped towards zero, so "-0.295"
+// and "0.295" both lose the same half cent.
+//
+// The decimal separator may be a comma, as in European exports, and thousands
+// may be separated by periods or commas: see normalizeDecimal.
+func ParseDecimalPlaces(s string, places int) (Decimal, error) {
+	return parseDecimalPlaces(s, places, false)
+}
+
+// parseDecimalPlaces is ParseDecimalPlaces, rounding the digits beyond the
+// places half away from zero rather than truncating them when round is set.
+func parseDecimalPlaces(s string, places int, round bool) (Decimal, error) {
+	if places < 0 || places > maxPlaces {
+		return Decimal{}, fmt.Errorf("Invalid number of decimal places: %d", places)
+	}
+
+	str := normalizeDecimal(s, places)
+	neg := false
+	if str != "" && (str[0] == '-' || str[0] == '+') {
+		neg = str[0] == '-'
+		str = str[1:]
+	}
+
+	whole, frac := str, ""
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		whole, frac = str[:i], str[i+1:]
+	}
+	if (whole == "" && frac == "") || !isDigits(whole) || !isDigits(frac) {
+		return Decimal{}, fmt.Errorf("Invalid decimal string: '%s'", s)
+	}
+
+	up := false
+	if len(frac) > places {
+		up = round && frac[places] >= '5'
+		frac = frac[:places]
+	}
+	frac += strings.Repeat("0", places-len(frac))
+
+	units, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err == nil && up {
+		if units == math.MaxInt64 {
+			err = strconv.ErrRange
+		}
+		units++
+	}
+	if err != nil {
+		return Decimal{}, fmt.Errorf("Invalid decimal string: '%s'", s)
+	}
+	if neg {
+		units = -units
+	}
+	return Decimal{units: units, places: uint8(places)}, nil
+}
+
+// parseDecimalExact parses s keeping as many decimal places as it was
+// written with.
+func parseDecimalExact(s string) (Decimal, error) {
+	places := 0
+	if n := normalizeDecimal(s, maxPlaces); strings.IndexByte(n, '.') >= 0 {
+		places = len(n) - strings.IndexByte(n, '.') - 1
+	}
+	if places > maxPlaces {
+		return Decimal{}, fmt.Errorf("Invalid decimal string: '%s'", s)
+	}
+	return ParseDecimalPlaces(s, places)
+}
+
+// ExcessPlaces chooses what happens to the digits of an amount beyond the
+// decimal places of its currency, such as the third of 12.345 in USD.
+type ExcessPlaces int
+
+const (
+	// TruncateExcessPlaces drops the digits towards zero, so 12.345 is
+	// 12.34 and -12.345 is -12.34. It is the default.
+	TruncateExcessPlaces ExcessPlaces = iota
+
+	// RoundExcessPlaces rounds half away from zero, like
+	// NewDecialFromFloat64, so 12.345 is 12.35 and -12.345 is -12.35.
+	RoundExcessPlaces
+
+	// KeepExcessPlaces keeps every digit as written, so 12.345 stays
+	// 12.345 with three places. Amounts written with fewer digits still
+	// get the places of their currency.
+	KeepExcessPlaces
+)
+
+// parseAmount parses an amount in a currency with the given number of
+// places, handling any digits beyond them as excess says.
+func parseAmount(s string, places int, excess ExcessPlaces) (Decimal, error) {
+	switch excess {
+	case RoundExcessPlaces:
+		return parseDecimalPlaces(s, places, true)
+	case KeepExcessPlaces:
+		// Separators are read as for the currency, so that "1,234" is
+		// still 1234 in one with cents.
+		if n := normalizeDecimal(s, places); strings.IndexByte(n, '.') >= 0 {
+			if written := len(n) - strings.IndexByte(n, '.') - 1; written > places && written <= maxPlaces {
+				places = written
+			}
+		}
+	}
+	return ParseDecimalPlaces(s, places)
+}
+
+// parseNumber parses a float such as a unit count or exchange rate, which
+// may be written with a comma decimal separator like an amount.
+func parseNumber(s string) (float64, error) {
+	return strconv.ParseFloat(normalizeDecimal(s, maxPlaces), 64)
+}
+
+// normalizeDecimal rewrites a number written with a comma decimal separator
+// or with thousands separators, e.g. "12,34", "1.234,56" or "1,234.56", to
+// the plain form "1234.56". When both a period and a comma appear, the last
+// one is the decimal separator. A repeated separator is a thousands
+// separator. A lone comma is one too if exactly three digits follow a
+// non-zero whole part and fewer than three decimal places are expected;
+// "1,234" is 1234 in a currency with cents but 1.234 in one with three
+// places. A lone period is always the decimal separator, as OFX writes it,
+// so "1.234" is 1.234 and any other number of digits after a lone comma, as
+// in "1,2345", makes it the decimal separator too. Currency symbols
+// and whitespace are removed first: see stripCurrency. Anything else is
+// returned unchanged, to be rejected by the caller.
+func normalizeDecimal(s string, places int) string {
+	s = stripCurrency(s)
+	comma, period := strings.LastIndexByte(s, ','), strings.LastIndexByte(s, '.')
+	if comma < 0 && strings.Count(s, ".") <= 1 {
+		return s
+	}
+
+	var decimal, thousands string
+	switch {
+	case comma >= 0 && period >= 0:
+		decimal, thousands = ".", ","
+		if comma > period {
+			decimal, thousands = ",", "."
+		}
+	default:
+		sep, i := ",", comma
+		if comma < 0 {
+			sep, i = ".", period
+		}
+		whole := strings.TrimLeft(s[:i], "+-")
+		grouped := strings.Count(s, sep) > 1 ||
+			(len(s)-i-1 == 3 && places < 3 && whole != "" && whole[0] != '0')
+		if grouped {
+			thousands = sep
+		} else {
+			decimal = sep
+		}
+	}
+
+	whole, frac := s, ""
+	if decimal != "" {
+		i := strings.LastIndex(s, decimal)
+		whole, frac = s[:i], s[i+1:]
+	}
+	if thousands != "" {
+		groups := strings.Split(strings.TrimLeft(whole, "+-"), thousands)
+		for i, g := range groups {
+			if len(g) != 3 && (i > 0 || g == "" || len(g) > 3) {
+				return s
+			}
+		}
+		whole = strings.ReplaceAll(whole, thousands, "")
+	}
+	if decimal == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
+
+// currencySymbols are the symbols stripped from amounts by stripCurrency.
+const currencySymbols = "$€£¥₹₩₽₺₪₫₱"
+
+// stripCurrency removes a currency symbol or ISO 4217 code written before
+// or after the number of an amount, and the whitespace around it and between
+// digit groups, as some non-conformant exports write "$ 12.34", "-€5,00",
+// "12.34 USD" or "1 234,56". A sign stays attached to the number, so "- 5"
+// is still rejected, as are other letters, codes that are not ISO 4217 and
+// spaces that do not separate groups of three digits, as in "1 2 3".
+func stripCurrency(s string) string {
+	plain := true
+	for i := 0; i < len(s) && plain; i++ {
+		plain = strings.IndexByte("0123456789+-.,", s[i]) >= 0
+	}
+	if plain {
+		return s
+	}
+
+	s = strings.TrimFunc(s, unicode.IsSpace)
+	sign := ""
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		sign, s = s[:1], s[1:]
+	}
+	stripped := false
+	if r, n := utf8.DecodeRuneInString(s); n > 0 && strings.ContainsRune(currencySymbols, r) {
+		s, stripped = strings.TrimLeftFunc(s[n:], unicode.IsSpace), true
+	} else if len(s) > 3 && isCurrencyCode(s[:3]) {
+		s, stripped = strings.TrimLeftFunc(s[3:], unicode.IsSpace), true
+	}
+	if stripped && sign == "" && s != "" && (s[0] == '-' || s[0] == '+') {
+		sign, s = s[:1], s[1:]
+	}
+	if !stripped {
+		if r, n := utf8.DecodeLastRuneInString(s); n > 0 && n < len(s) && strings.ContainsRune(currencySymbols, r) {
+			s = strings.TrimRightFunc(s[:len(s)-n], unicode.IsSpace)
+		} else if len(s) > 3 && isCurrencyCode(s[len(s)-3:]) {
+			s = strings.TrimRightFunc(s[:len(s)-3], unicode.IsSpace)
+		}
+	}
+
+	// Join the thousands groups of the whole part separated by spaces, as
+	// in "1 234,56". Any other space is kept, for ParseDecimal to reject.
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsSpace(r) && !strings.ContainsAny(s[:i], ".,") {
+			prev := len(s[:i]) - len(strings.TrimRight(s[:i], "0123456789"))
+			rest := s[i+utf8.RuneLen(r):]
+			next := len(rest) - len(strings.TrimLeft(rest, "0123456789"))
+			if prev >= 1 && prev <= 3 && next == 3 {
+				continue
+			}
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String()
+}
+
+// currencyCodes lists the active ISO 4217 currency codes accepted by
+// stripCurrency.
+var currencyCodes = map[string]bool{}
+
+func init() {
+	for _, code := range strings.Fields(`
+		AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD
+		BND BOB BOV BRL BSD BTN BWP BYN BZD CAD CDF CHE CHF CHW CLF CLP CNY
+		COP COU CRC CUC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB EUR FJD FKP
+		GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR IQD IRR
+		ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD
+		LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MXV MYR MZN
+		NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD
+		RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SLL SOS SRD SSP STN SVC SYP
+		SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX USD USN UYI UYU UYW
+		UZS VED VES VND VUV WST XAF XAG XAU XCD XDR XOF XPD XPF XPT YER ZAR
+		ZMW ZWL`) {
+		currencyCodes[code] = true
+	}
+}
+
+func isCurrencyCode(s string) bool {
+	return currencyCodes[s]
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewDecialFromFloat64 converts an amount in whole currency units to cents,
+// rounding half away from zero so that positive and negative amounts are
+// treated alike.
+func NewDecialFromFloat64(f float64) Decimal {
+	x := math.Round(f * 100)
+	return Decimal{units: int64(x), places: 2}
+}
+
+// currencyPlaces lists the ISO 4217 currencies whose minor unit is not a
+// hundredth.
+var currencyPlaces = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0,
+	"XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// CurrencyPlaces returns the number of decimal places used by amounts in
+// the given ISO 4217 currency, defaulting to two.
+func CurrencyPlaces(currency string) int {
+	if places, ok := currencyPlaces[strings.ToUpper(currency)]; ok {
+		return places
+	}
+	return 2
+}
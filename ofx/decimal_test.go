@@ -0,0 +1,439 @@
+package ofx
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	d, err := ParseDecimal("-12.50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.String() != "-12.50" {
+		t.Errorf("Wrong decimal. Expected: %s Actual: %s\n", "-12.50", d)
+	}
+
+	if _, err := ParseDecimal("abc"); err == nil {
+		t.Errorf("Expected an error parsing 'abc'\n")
+	}
+
+	if d := NewDecial("abc"); d != (Decimal{}) {
+		t.Errorf("Expected lenient parse to yield zero. Actual: %s\n", d)
+	}
+}
+
+func TestParseDecimalExact(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected int64
+	}{
+		{"0.29", 29},
+		{"1.10", 110},
+		{"19.99", 1999},
+		{"4.35", 435},
+		{"1.1", 110},
+		{"5", 500},
+		{".5", 50},
+		{"0.019", 1},
+		{"92233720368547758.07", 9223372036854775807},
+	}
+
+	for _, test := range tests {
+		actual, err := ParseDecimal(test.in)
+		if err != nil {
+			t.Errorf("Failed to parse %s: %v\n", test.in, err)
+			continue
+		}
+		if expected := DecimalFromUnits(test.expected, 2); actual != expected {
+			t.Errorf("Wrong cents for %s. Expected: %s Actual: %s\n", test.in, expected, actual)
+		}
+	}
+
+	for _, in := range []string{"", ".", "-", "1.2.3", "1e3", "12a", "92233720368547758.08"} {
+		if _, err := ParseDecimal(in); err == nil {
+			t.Errorf("Expected an error parsing '%s'\n", in)
+		}
+	}
+}
+
+func TestParseDecimalSigns(t *testing.T) {
+	tests := []struct {
+		in       string
+		units    int64
+		expected string
+	}{
+		{"-0.01", -1, "-0.01"},
+		{"-1234.56", -123456, "-1234.56"},
+		{"+5.00", 500, "5.00"},
+		{"-0.295", -29, "-0.29"},
+		{"0.295", 29, "0.29"},
+		{"-0.00", 0, "0.00"},
+		{"-.5", -50, "-0.50"},
+	}
+
+	for _, test := range tests {
+		d, err := ParseDecimal(test.in)
+		if err != nil {
+			t.Errorf("Failed to parse %s: %v\n", test.in, err)
+			continue
+		}
+		if d != DecimalFromUnits(test.units, 2) || d.String() != test.expected {
+			t.Errorf("Wrong decimal for %s. Expected: %s Actual: %s\n", test.in, test.expected, d)
+		}
+	}
+
+	for _, in := range []string{"+", "--5", "+-5", "- 5", "5-"} {
+		if _, err := ParseDecimal(in); err == nil {
+			t.Errorf("Expected an error parsing '%s'\n", in)
+		}
+	}
+
+	if d := DecimalFromUnits(-9223372036854775808, 2); d.String() != "-92233720368547758.08" {
+		t.Errorf("Wrong minimum decimal. Actual: %s\n", d)
+	}
+}
+
+func TestNewDecialFromFloat64(t *testing.T) {
+	for f, expected := range map[float64]string{0.29: "0.29", -0.295: "-0.30", 0.295: "0.30", -42: "-42.00"} {
+		if d := NewDecialFromFloat64(f); d.String() != expected {
+			t.Errorf("Wrong decimal for %g. Expected: %s Actual: %s\n", f, expected, d)
+		}
+	}
+}
+
+func TestParseDecimalPlaces(t *testing.T) {
+	tests := []struct {
+		currency string
+		in       string
+		expected string
+		float    float64
+	}{
+		{"JPY", "1500", "1500", 1500},
+		{"JPY", "-1500.00", "-1500", -1500},
+		{"BHD", "12.345", "12.345", 12.345},
+		{"BHD", "-0.5", "-0.500", -0.5},
+		{"USD", "12.345", "12.34", 12.34},
+		{"", "0.01", "0.01", 0.01},
+	}
+
+	for _, test := range tests {
+		d, err := ParseDecimalPlaces(test.in, CurrencyPlaces(test.currency))
+		if err != nil {
+			t.Errorf("Failed to parse %s %s: %v\n", test.currency, test.in, err)
+			continue
+		}
+		if d.String() != test.expected {
+			t.Errorf("Wrong string for %s %s. Expected: %s Actual: %s\n", test.currency, test.in, test.expected, d)
+		}
+		if d.Float64() != test.float {
+			t.Errorf("Wrong float for %s %s. Expected: %g Actual: %g\n", test.currency, test.in, test.float, d.Float64())
+		}
+	}
+}
+
+func TestParseAmountExcessPlaces(t *testing.T) {
+	tests := []struct {
+		in       string
+		excess   ExcessPlaces
+		expected string
+	}{
+		{"12.345", TruncateExcessPlaces, "12.34"},
+		{"12.345", RoundExcessPlaces, "12.35"},
+		{"12.345", KeepExcessPlaces, "12.345"},
+		{"-12.345", RoundExcessPlaces, "-12.35"},
+		{"12.3449", RoundExcessPlaces, "12.34"},
+		{"0.995", RoundExcessPlaces, "1.00"},
+		{"12.3", KeepExcessPlaces, "12.30"},
+		{"1,234", KeepExcessPlaces, "1234.00"},
+		{"1.234,567", KeepExcessPlaces, "1234.567"},
+	}
+
+	for _, test := range tests {
+		d, err := parseAmount(test.in, 2, test.excess)
+		if err != nil {
+			t.Errorf("Failed to parse %s: %v\n", test.in, err)
+			continue
+		}
+		if d.String() != test.expected {
+			t.Errorf("Wrong decimal for %s (%d). Expected: %s Actual: %s\n", test.in, test.excess, test.expected, d)
+		}
+	}
+
+	if _, err := parseAmount("92233720368547758.075", 2, RoundExcessPlaces); err == nil {
+		t.Errorf("Expected an error rounding past the largest amount\n")
+	}
+}
+
+func TestParseDecimalSeparators(t *testing.T) {
+	tests := []struct {
+		currency string
+		in       string
+		expected string
+	}{
+		{"EUR", "12,34", "12.34"},
+		{"EUR", "-0,05", "-0.05"},
+		{"EUR", "1.234,56", "1234.56"},
+		{"USD", "1,234.56", "1234.56"},
+		{"USD", "1,234,567.89", "1234567.89"},
+		{"EUR", "1.234.567", "1234567.00"},
+		{"USD", "1,234", "1234.00"},
+		{"BHD", "1,234", "1.234"},
+		{"EUR", "0,923", "0.92"},
+		{"USD", "1.234", "1.23"},
+		{"BHD", "1.234", "1.234"},
+		{"USD", "1,2345", "1.23"},
+	}
+
+	for _, test := range tests {
+		d, err := ParseDecimalPlaces(test.in, CurrencyPlaces(test.currency))
+		if err != nil {
+			t.Errorf("Failed to parse %s %s: %v\n", test.currency, test.in, err)
+			continue
+		}
+		if d.String() != test.expected {
+			t.Errorf("Wrong string for %s %s. Expected: %s Actual: %s\n", test.currency, test.in, test.expected, d)
+		}
+	}
+
+	for _, in := range []string{"12,3,4", "1.2.3", "1,,234", "1.234,5.6"} {
+		if d, err := ParseDecimal(in); err == nil {
+			t.Errorf("Expected an error for %s. Actual: %s\n", in, d)
+		}
+	}
+}
+
+func TestParseDecimalCurrencySymbols(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"$12.34", "12.34"},
+		{" 12.34 ", "12.34"},
+		{"USD 12.34", "12.34"},
+		{"$ 12.34", "12.34"},
+		{"-$12.34", "-12.34"},
+		{"$-12.34", "-12.34"},
+		{"12.34 USD", "12.34"},
+		{"€1.234,56", "1234.56"},
+		{"1 234,56 €", "1234.56"},
+		{"£\u00a05.00", "5.00"},
+		{"-1 234 567.89 EUR", "-1234567.89"},
+	}
+
+	for _, test := range tests {
+		d, err := ParseDecimal(test.in)
+		if err != nil {
+			t.Errorf("Failed to parse %q: %v\n", test.in, err)
+			continue
+		}
+		if d.String() != test.expected {
+			t.Errorf("Wrong string for %q. Expected: %s Actual: %s\n", test.in, test.expected, d)
+		}
+	}
+
+	for _, in := range []string{"$", "USD", "$abc", "12.34 dollars", "US 12.34", "$12.34$", "12$34", "--$12",
+		"ABC12", "TBD 5", "ZZZ-3.00", "1 2 3", "1234 567", "1 23,45", "1,234 567"} {
+		if d, err := ParseDecimal(in); err == nil {
+			t.Errorf("Expected an error for %q. Actual: %s\n", in, d)
+		}
+	}
+}
+
+func TestParseCommaAmounts(t *testing.T) {
+	_ofx := parseFile(t, "testdata/comma.ofx")
+
+	expected := []int64{-1234, 250000, -5}
+	for i, trans := range _ofx.Transactions {
+		if trans.Amount.Cents() != expected[i] {
+			t.Errorf("Wrong cents for %s. Expected: %d Actual: %d\n", trans.FitID, expected[i], trans.Amount.Cents())
+		}
+	}
+
+	if _ofx.LedgerBalance.Cents() != 348761 {
+		t.Errorf("Wrong ledger balance. Expected: 3487.61 Actual: %s\n", _ofx.LedgerBalance)
+	}
+	if rate := _ofx.Transactions[2].CurrencyRate; rate != 1.1603 {
+		t.Errorf("Wrong currency rate. Expected: 1.1603 Actual: %g\n", rate)
+	}
+}
+
+func TestParseStatementCurrencyPlaces(t *testing.T) {
+	for _, test := range []struct {
+		currency, amount, balance string
+	}{
+		{"JPY", "-1200", "98800"},
+		{"KWD", "-12.250", "987.750"},
+	} {
+		in := `<OFX><STMTRS><CURDEF>` + test.currency + `<BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT<TRNAMT>` + test.amount + `<FITID>1</STMTTRN>
+</BANKTRANLIST><LEDGERBAL><BALAMT>` + test.balance + `</LEDGERBAL></STMTRS></OFX>`
+
+		_ofx, err := Parse(strings.NewReader(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := _ofx.Transactions[0].Amount.String(); actual != test.amount {
+			t.Errorf("Wrong %s amount. Expected: %s Actual: %s\n", test.currency, test.amount, actual)
+		}
+		if actual := _ofx.LedgerBalance.String(); actual != test.balance {
+			t.Errorf("Wrong %s balance. Expected: %s Actual: %s\n", test.currency, test.balance, actual)
+		}
+	}
+}
+
+func TestParseInvalidAmount(t *testing.T) {
+	in := `<OFX><BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT<TRNAMT>abc<FITID>300001</STMTTRN>
+</BANKTRANLIST></OFX>`
+
+	_ofx, err := Parse(strings.NewReader(in))
+	if err == nil {
+		t.Fatalf("Expected an error. Actual: %v\n", _ofx)
+	}
+
+	for _, s := range []string{"TRNAMT", "300001", "'abc'"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("Error does not mention %s: %v\n", s, err)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	// Summed as floats these drift away from the exact total.
+	amounts := []string{"0.10", "0.20", "-0.07", "19.99", "1000.01", "-0.33", "0.10"}
+
+	var sum Decimal
+	var expected int64
+	for _, a := range amounts {
+		d := NewDecial(a)
+		sum = sum.Add(d)
+		expected += d.Cents()
+	}
+	if sum.Cents() != 102000 || expected != 102000 {
+		t.Errorf("Wrong sum. Expected: 102000 Actual: %d (%d)\n", sum.Cents(), expected)
+	}
+	if sum.String() != "1020.00" {
+		t.Errorf("Wrong sum. Expected: 1020.00 Actual: %s\n", sum)
+	}
+
+	if diff := sum.Sub(NewDecial("1020.01")); diff.Cents() != -1 {
+		t.Errorf("Wrong difference. Expected: -1 Actual: %d\n", diff.Cents())
+	}
+
+	for _, test := range []struct {
+		d        Decimal
+		expected int
+	}{{NewDecial("-0.01"), -1}, {DecimalFromUnits(-1, 3), -1}, {NewDecial("0.00"), 0}, {Decimal{}, 0}, {DecimalFromUnits(1, 3), 1}} {
+		if actual := test.d.Sign(); actual != test.expected {
+			t.Errorf("Wrong sign for %s. Expected: %d Actual: %d\n", test.d, test.expected, actual)
+		}
+	}
+}
+
+func TestDecimalOverflow(t *testing.T) {
+	tiny, large := DecimalFromUnits(1, 18), DecimalFromUnits(9000000000, 0)
+	for name, f := range map[string]func(){
+		"Add":   func() { tiny.Add(large) },
+		"Sub":   func() { large.Sub(tiny) },
+		"sum":   func() { DecimalFromUnits(math.MaxInt64, 2).Add(NewDecial("0.01")) },
+		"Cents": func() { DecimalFromUnits(math.MaxInt64, 0).Cents() },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected %s to panic on overflow.\n", name)
+				}
+			}()
+			f()
+		}()
+	}
+
+	// Comparing does not overflow: the rescaled side is the larger.
+	if tiny.cmp(large) != -1 || large.cmp(tiny) != 1 || DecimalFromUnits(-9000000000, 0).cmp(tiny) != -1 {
+		t.Errorf("Wrong comparison of %s and %s\n", tiny, large)
+	}
+}
+
+func TestDecimalSumTransactions(t *testing.T) {
+	_ofx := parseFile(t, "testdata/v103.ofx")
+
+	var sum Decimal
+	for _, trans := range _ofx.Transactions {
+		sum = sum.Add(trans.Amount)
+	}
+	if sum.Cents() != 25000 {
+		t.Errorf("Wrong transaction total. Expected: 25000 Actual: %d\n", sum.Cents())
+	}
+}
+
+func TestDecimalCents(t *testing.T) {
+	tests := []struct {
+		d        Decimal
+		expected int64
+	}{
+		{DecimalFromUnits(1234, 2), 1234},
+		{DecimalFromUnits(1234, 0), 123400},
+		{DecimalFromUnits(-12345, 3), -1234},
+		{Decimal{}, 0},
+	}
+	for _, test := range tests {
+		if actual := test.d.Cents(); actual != test.expected {
+			t.Errorf("Wrong cents for %s. Expected: %d Actual: %d\n", test.d, test.expected, actual)
+		}
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	tests := []struct {
+		d        Decimal
+		expected string
+	}{
+		{DecimalFromUnits(1234, 2), "12.34"},
+		{DecimalFromUnits(-50, 2), "-0.50"},
+		{DecimalFromUnits(1500, 0), "1500"},
+		{DecimalFromUnits(1234, 3), "1.234"},
+		{Decimal{}, "0"},
+	}
+	for _, test := range tests {
+		res, err := json.Marshal(test.d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(res) != test.expected {
+			t.Errorf("Wrong json. Expected: %s Actual: %s\n", test.expected, res)
+		}
+
+		var d Decimal
+		if err := json.Unmarshal(res, &d); err != nil {
+			t.Fatal(err)
+		}
+		if d != test.d {
+			t.Errorf("Wrong round trip of %s. Expected: %#v Actual: %#v\n", test.expected, test.d, d)
+		}
+	}
+
+	var d Decimal
+	if err := json.Unmarshal([]byte(`"12.34"`), &d); err != nil || d != DecimalFromUnits(1234, 2) {
+		t.Errorf("Wrong decimal from json string. Expected: 12.34 Actual: %s (%v)\n", d, err)
+	}
+	for _, in := range []string{`1e3`, `"abc"`, `true`} {
+		if err := json.Unmarshal([]byte(in), &d); err == nil {
+			t.Errorf("Expected an error for %s\n", in)
+		}
+	}
+}
+
+func TestMarshalTransactionAmount(t *testing.T) {
+	_ofx := parseFile(t, "testdata/v103.ofx")
+
+	res, err := json.Marshal(_ofx.Transactions[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(res), `"amount":-100.00`) {
+		t.Errorf("Wrong amount json. Expected: -100.00 Actual: %s\n", res)
+	}
+}
@@ -0,0 +1,108 @@
+package ofx
+
+import "reflect"
+
+// StatementDiff describes what changed between two downloads of a
+// statement, going from the first to the second. Transactions are matched by
+// FITID, so a transaction with a synthetic FITID that changed shows up as
+// removed and added. The balance deltas are the new balance less the old.
+type StatementDiff struct {
+	Added                 []*OfxTransaction   `json:"added"`
+	Removed               []*OfxTransaction   `json:"removed"`
+	Changed               []TransactionChange `json:"changed"`
+	LedgerBalanceDelta    Decimal             `json:"ledger_balance_delta"`
+	AvailableBalanceDelta Decimal             `json:"available_balance_delta"`
+}
+
+// TransactionChange is a transaction present in both statements whose
+// fields differ. Fields holds the JSON names of those fields, e.g. amount.
+type TransactionChange struct {
+	Old    *OfxTransaction `json:"old"`
+	New    *OfxTransaction `json:"new"`
+	Fields []string        `json:"fields"`
+}
+
+// Empty reports whether the statements had the same transactions and
+// balances.
+func (d StatementDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 &&
+		d.LedgerBalanceDelta.cmp(Decimal{}) == 0 && d.AvailableBalanceDelta.cmp(Decimal{}) == 0
+}
+
+// transactionFields lists the fields of a transaction compared by Diff.
+// Those computed by the parser or by methods such as ComputeRunningBalances
+// are left out.
+var transactionFields = []struct {
+	name  string
+	equal func(a, b *OfxTransaction) bool
+}{
+	{"type", func(a, b *OfxTransaction) bool { return a.Type == b.Type }},
+	{"posted_datetime", func(a, b *OfxTransaction) bool { return a.PostedDateTime.Equal(b.PostedDateTime) }},
+	{"user_datetime", func(a, b *OfxTransaction) bool { return a.UserDateTime.Equal(b.UserDateTime) }},
+	{"amount", func(a, b *OfxTransaction) bool { return a.Amount.cmp(b.Amount) == 0 }},
+	{"currency", func(a, b *OfxTransaction) bool { return a.Currency == b.Currency }},
+	{"currency_rate", func(a, b *OfxTransaction) bool { return a.CurrencyRate == b.CurrencyRate }},
+	{"original_currency", func(a, b *OfxTransaction) bool { return a.OriginalCurrency == b.OriginalCurrency }},
+	{"check_num", func(a, b *OfxTransaction) bool { return a.CheckNum == b.CheckNum }},
+	{"name", func(a, b *OfxTransaction) bool { return a.Name == b.Name }},
+	{"memo", func(a, b *OfxTransaction) bool { return a.Memo == b.Memo }},
+	{"sic", func(a, b *OfxTransaction) bool { return a.SIC == b.SIC }},
+	{"payee", func(a, b *OfxTransaction) bool { return reflect.DeepEqual(a.Payee, b.Payee) }},
+	{"destination_account", func(a, b *OfxTransaction) bool { return reflect.DeepEqual(a.DestinationAccount, b.DestinationAccount) }},
+}
+
+// Diff compares o with other, a later download of the same account. Added
+// and Changed are in the order of other, Removed in the order of o. When a
+// FITID repeats within a statement only its first transaction is compared.
+func (o *Ofx) Diff(other *Ofx) StatementDiff {
+	old := firstByFitID(o.Transactions)
+	current := firstByFitID(other.Transactions)
+
+	var d StatementDiff
+	for _, t := range other.Transactions {
+		if current[t.FitID] != t {
+			continue
+		}
+		prev, ok := old[t.FitID]
+		if !ok {
+			d.Added = append(d.Added, t)
+			continue
+		}
+		var fields []string
+		for _, f := range transactionFields {
+			if !f.equal(prev, t) {
+				fields = append(fields, f.name)
+			}
+		}
+		if fields != nil {
+			d.Changed = append(d.Changed, TransactionChange{Old: prev, New: t, Fields: fields})
+		}
+	}
+	for _, t := range o.Transactions {
+		if _, ok := current[t.FitID]; !ok && old[t.FitID] == t {
+			d.Removed = append(d.Removed, t)
+		}
+	}
+
+	d.LedgerBalanceDelta = other.LedgerBalance.Sub(o.LedgerBalance)
+	d.AvailableBalanceDelta = other.AvailableBalance.Sub(o.AvailableBalance)
+	return d
+}
+
+// Equal reports whether o and other hold the same transactions and
+// balances, that is whether their Diff is empty. Other fields, such as the
+// time the statements were generated, are not compared.
+func (o *Ofx) Equal(other *Ofx) bool {
+	return o.Diff(other).Empty()
+}
+
+// firstByFitID maps each FITID to the first transaction that has it.
+func firstByFitID(transactions []*OfxTransaction) map[string]*OfxTransaction {
+	m := make(map[string]*OfxTransaction, len(transactions))
+	for _, t := range transactions {
+		if _, ok := m[t.FitID]; !ok {
+			m[t.FitID] = t
+		}
+	}
+	return m
+}
@@ -0,0 +1,42 @@
+package ofx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := parseFile(t, "testdata/monthly-jan.ofx")
+	redownload := parseFile(t, "testdata/monthly-jan-redownload.ofx")
+
+	d := old.Diff(redownload)
+	if len(d.Added) != 1 || d.Added[0].FitID != "M6" {
+		t.Errorf("Wrong added transactions. Expected: [M6] Actual: %v\n", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].FitID != "M1" {
+		t.Errorf("Wrong removed transactions. Expected: [M1] Actual: %v\n", d.Removed)
+	}
+	if len(d.Changed) != 1 {
+		t.Fatalf("Wrong number of changed transactions. Expected: 1 Actual: %d\n", len(d.Changed))
+	}
+	c := d.Changed[0]
+	if c.Old.FitID != "M2" || c.Old.Amount.String() != "-45.10" || c.New.Amount.String() != "-54.10" {
+		t.Errorf("Wrong changed transaction. Expected: M2 -45.10 -54.10 Actual: %s %s %s\n", c.Old.FitID, c.Old.Amount, c.New.Amount)
+	}
+	if expected := []string{"amount", "memo"}; !reflect.DeepEqual(c.Fields, expected) {
+		t.Errorf("Wrong changed fields. Expected: %v Actual: %v\n", expected, c.Fields)
+	}
+	if d.LedgerBalanceDelta.String() != "-14.00" {
+		t.Errorf("Wrong ledger balance delta. Expected: -14.00 Actual: %s\n", d.LedgerBalanceDelta)
+	}
+	if d.Empty() || old.Equal(redownload) {
+		t.Errorf("Expected the statements to differ.\n")
+	}
+
+	// The generated time is not compared.
+	again := parseFile(t, "testdata/monthly-jan.ofx")
+	again.GeneratedDateTime = redownload.GeneratedDateTime
+	if d := old.Diff(again); !d.Empty() || !old.Equal(again) {
+		t.Errorf("Expected no differences. Actual: %+v\n", d)
+	}
+}
@@ -0,0 +1,19 @@
+package ofx
+
+import (
+	"errors"
+)
+
+// ErrNotOFX is returned, possibly wrapped, when the input is not an OFX
+// document at all, such as the HTML login page some banks serve in place of
+// an expired download.
+var ErrNotOFX = errors.New("Not an OFX document")
+
+// ErrUnsupportedVersion is returned, possibly wrapped, when the header
+// declares an OFX version other than 1.x or 2.x.
+var ErrUnsupportedVersion = errors.New("Unsupported OFX version")
+
+// ErrNotStatement is returned, possibly wrapped, when the input is an OFX
+// response holding no statement, only message sets such as the profile
+// (<PROFMSGSRSV1>) or account information (<SIGNUPMSGSRSV1>) responses.
+var ErrNotStatement = errors.New("Not a statement response")
@@ -0,0 +1,55 @@
+package ofx
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseNotOFX(t *testing.T) {
+	f, err := os.Open("testdata/login.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := Parse(f); !errors.Is(err, ErrNotOFX) {
+		t.Errorf("Expected ErrNotOFX for an HTML page. Actual: %v\n", err)
+	}
+
+	for _, in := range []string{"", "just some text\n", "{\"error\": \"expired\"}"} {
+		if _, err := Parse(strings.NewReader(in)); !errors.Is(err, ErrNotOFX) {
+			t.Errorf("Expected ErrNotOFX for %q. Actual: %v\n", in, err)
+		}
+	}
+}
+
+func TestParseUnsupportedVersion(t *testing.T) {
+	for _, header := range []string{
+		"OFXHEADER:300\nVERSION:300\n\n",
+		"OFXHEADER:100\nVERSION:9\n\n",
+		`<?xml version="1.0"?><?OFX OFXHEADER="200" VERSION="320"?>`,
+	} {
+		in := header + "<OFX></OFX>"
+		if _, err := Parse(strings.NewReader(in)); !errors.Is(err, ErrUnsupportedVersion) {
+			t.Errorf("Expected ErrUnsupportedVersion for %q. Actual: %v\n", header, err)
+		}
+	}
+}
+
+func TestParseNotStatement(t *testing.T) {
+	f, err := os.Open("testdata/profile.ofx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_, err = Parse(f)
+	if !errors.Is(err, ErrNotStatement) {
+		t.Fatalf("Expected ErrNotStatement for a profile response. Actual: %v\n", err)
+	}
+	if !strings.Contains(err.Error(), "PROFMSGSRSV1") {
+		t.Errorf("Expected the error to name the message set. Actual: %v\n", err)
+	}
+}
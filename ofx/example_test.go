@@ -0,0 +1,32 @@
+package ofx_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/daniellawrence/ofx2json/ofx"
+)
+
+func ExampleParse() {
+	f, err := os.Open("testdata/v103.ofx")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	statement, err := ofx.Parse(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(statement.AccountNumber, statement.AccountType, statement.Currency)
+	for _, t := range statement.Transactions {
+		fmt.Println(t.PostedDateTime.Format("2006-01-02"), t.Amount, t.Name)
+	}
+	// Output:
+	// 098-121 SAVINGS USD
+	// 2007-03-15 200.00 DEPOSIT
+	// 2007-03-29 150.00 TRANSFER
+	// 2007-07-09 -100.00 John Hancock
+}
@@ -0,0 +1,24 @@
+package ofx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// syntheticFitIDPrefix starts every generated FITID, so that it can not be
+// mistaken for one assigned by a bank.
+const syntheticFitIDPrefix = "SYN"
+
+// syntheticFitID returns the FITID given to a transaction that has none: a
+// hash of its posting date, amount, name and memo. Transactions that agree on
+// all four get the same id, so the parser numbers repeats within a
+// statement.
+func syntheticFitID(t *OfxTransaction) string {
+	h := sha256.New()
+	for _, s := range []string{t.PostedDateTime.UTC().Format(time.RFC3339Nano), t.Amount.String(), t.Name, t.Memo} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return syntheticFitIDPrefix + hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,35 @@
+package ofx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSyntheticFitID(t *testing.T) {
+	first := parseFile(t, "testdata/nofitid.ofx")
+	second := parseFile(t, "testdata/nofitid.ofx")
+
+	seen := map[string]bool{}
+	for i, trans := range first.Transactions[:3] {
+		if !trans.SyntheticFitID || !strings.HasPrefix(trans.FitID, syntheticFitIDPrefix) {
+			t.Errorf("Expected a synthetic FITID for transaction %d. Actual: %s %v\n", i, trans.FitID, trans.SyntheticFitID)
+		}
+		if seen[trans.FitID] {
+			t.Errorf("Repeated synthetic FITID: %s\n", trans.FitID)
+		}
+		seen[trans.FitID] = true
+
+		if again := second.Transactions[i].FitID; again != trans.FitID {
+			t.Errorf("Expected a stable FITID for transaction %d. Expected: %s Actual: %s\n", i, trans.FitID, again)
+		}
+	}
+
+	// The identical second transaction is numbered after the first.
+	if ids := first.Transactions; ids[1].FitID != ids[0].FitID+"-2" {
+		t.Errorf("Wrong FITID of the repeat. Expected: %s-2 Actual: %s\n", ids[0].FitID, ids[1].FitID)
+	}
+
+	if bank := first.Transactions[3]; bank.FitID != "N1" || bank.SyntheticFitID {
+		t.Errorf("Expected the bank's FITID to be kept. Actual: %s %v\n", bank.FitID, bank.SyntheticFitID)
+	}
+}
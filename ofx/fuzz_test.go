@@ -0,0 +1,29 @@
+package ofx
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func FuzzParse(f *testing.F) {
+	paths, err := filepath.Glob("testdata/*.*")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		_ofx, err := Parse(bytes.NewReader(b))
+		if (_ofx == nil) == (err == nil) {
+			t.Fatalf("Expected either a statement or an error. Actual: %v %v\n", _ofx, err)
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package ofx
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// maybeGunzip returns a reader of the decompressed contents of r when r
+// starts with the gzip magic bytes, as .ofx.gz downloads do, and r itself
+// otherwise.
+func maybeGunzip(r *bufio.Reader) (*bufio.Reader, error) {
+	b, err := r.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !bytes.Equal(b, gzipMagic) {
+		return r, nil
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return bufio.NewReader(gz), nil
+}
@@ -0,0 +1,15 @@
+package ofx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGzip(t *testing.T) {
+	expected := parseFile(t, "testdata/v103.ofx")
+	actual := parseFile(t, "testdata/v103.ofx.gz")
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Wrong gzip statement. Expected: %s Actual: %s\n", expected, actual)
+	}
+}
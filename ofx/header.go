@@ -0,0 +1,194 @@
+package ofx
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Header holds the fields of the block that precedes the OFX body: the
+// key:value lines of an OFX 1.x file or the <?xml?> and <?OFX?> processing
+// instructions of an OFX 2.x file.
+type Header struct {
+	OFXHeader   string `json:"ofx_header"`
+	Data        string `json:"data"`
+	Version     string `json:"version"`
+	Security    string `json:"security"`
+	Encoding    string `json:"encoding"`
+	Charset     string `json:"charset"`
+	Compression string `json:"compression"`
+	OldFileUID  string `json:"old_file_uid"`
+	NewFileUID  string `json:"new_file_uid"`
+}
+
+func (h *Header) set(key, value string) {
+	switch strings.ToUpper(key) {
+	case "OFXHEADER":
+		h.OFXHeader = value
+	case "DATA":
+		h.Data = value
+	case "VERSION":
+		h.Version = value
+	case "SECURITY":
+		h.Security = value
+	case "ENCODING":
+		h.Encoding = value
+	case "CHARSET":
+		h.Charset = value
+	case "COMPRESSION":
+		h.Compression = value
+	case "OLDFILEUID":
+		h.OldFileUID = value
+	case "NEWFILEUID":
+		h.NewFileUID = value
+	}
+}
+
+// The OFX dialects reported by Header.Dialect.
+const (
+	DialectSGML = "SGML"
+	DialectXML  = "XML"
+)
+
+// Dialect returns DialectXML for an OFX 2.x header, DialectSGML for an OFX
+// 1.x one, and "" when the input had no header to tell.
+func (h Header) Dialect() string {
+	switch {
+	case h.OFXHeader == "200" || strings.HasPrefix(h.Version, "2"):
+		return DialectXML
+	case h.OFXHeader == "100" || h.Data == "OFXSGML" || strings.HasPrefix(h.Version, "1"):
+		return DialectSGML
+	}
+	return ""
+}
+
+var headerAttr = regexp.MustCompile(`([A-Za-z]+)\s*=\s*"([^"]*)"`)
+
+// readHeader consumes the OFX header from r, leaving r positioned at the
+// first element of the OFX body.
+func readHeader(r *bufio.Reader) (Header, error) {
+	var h Header
+
+	for {
+		if err := skipSpace(r); err != nil {
+			if err == io.EOF {
+				return h, nil
+			}
+			return h, err
+		}
+
+		b, err := r.Peek(5)
+		if err != nil && err != io.EOF {
+			return h, err
+		}
+
+		switch {
+		case bytes.HasPrefix(b, []byte("<?xml")):
+			pi, err := readProcInst(r)
+			if err != nil {
+				return h, err
+			}
+			for _, m := range headerAttr.FindAllStringSubmatch(pi, -1) {
+				if m[1] == "encoding" {
+					h.Encoding = m[2]
+				}
+			}
+
+		case bytes.HasPrefix(b, []byte("<!--")):
+			if err := skipComment(r); err != nil {
+				return h, err
+			}
+
+		case bytes.HasPrefix(b, []byte("<?OFX")):
+			pi, err := readProcInst(r)
+			if err != nil {
+				return h, err
+			}
+			h.setProcInst(pi)
+			return h, nil
+
+		case len(b) > 0 && b[0] == '<':
+			return h, nil
+
+		default:
+			line, err := r.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return h, err
+			}
+			line = strings.TrimSpace(line)
+
+			i := strings.IndexByte(line, ':')
+			if i <= 0 {
+				return h, fmt.Errorf("%w: Invalid OFX header line: '%s'", ErrNotOFX, line)
+			}
+			h.set(line[:i], strings.TrimSpace(line[i+1:]))
+		}
+	}
+}
+
+func skipSpace(r *bufio.Reader) error {
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			return r.UnreadByte()
+		}
+	}
+}
+
+func skipComment(r *bufio.Reader) error {
+	var buf bytes.Buffer
+	for !bytes.HasSuffix(buf.Bytes(), []byte("-->")) {
+		c, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return fmt.Errorf("Unterminated comment in OFX header")
+			}
+			return err
+		}
+		buf.WriteByte(c)
+	}
+	return nil
+}
+
+// setProcInst sets the fields of an OFX 2.x <?OFX?> processing instruction.
+func (h *Header) setProcInst(pi string) {
+	for _, m := range headerAttr.FindAllStringSubmatch(pi, -1) {
+		h.set(m[1], m[2])
+	}
+}
+
+func readProcInst(r *bufio.Reader) (string, error) {
+	var buf bytes.Buffer
+	for !bytes.HasSuffix(buf.Bytes(), []byte("?>")) {
+		c, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return "", fmt.Errorf("Unterminated OFX header: '%s'", buf.String())
+			}
+			return "", err
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String(), nil
+}
+
+// checkVersion returns ErrUnsupportedVersion unless h is empty or declares an
+// OFX 1.x or 2.x document.
+func checkVersion(h Header) error {
+	switch h.OFXHeader {
+	case "", "100", "200":
+	default:
+		return fmt.Errorf("%w: OFXHEADER '%s'", ErrUnsupportedVersion, h.OFXHeader)
+	}
+
+	if v := h.Version; v != "" && (len(v) != 3 || !isDigits(v) || (v[0] != '1' && v[0] != '2')) {
+		return fmt.Errorf("%w: '%s'", ErrUnsupportedVersion, v)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package ofx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHeaderV1(t *testing.T) {
+	_ofx := parseFile(t, "testdata/v103.ofx")
+
+	expected := Header{
+		OFXHeader:   "100",
+		Data:        "OFXSGML",
+		Version:     "103",
+		Security:    "NONE",
+		Encoding:    "USASCII",
+		Charset:     "1252",
+		Compression: "NONE",
+		OldFileUID:  "NONE",
+		NewFileUID:  "NONE",
+	}
+	if _ofx.Header != expected {
+		t.Errorf("Wrong header. Expected: %+v Actual: %+v\n", expected, _ofx.Header)
+	}
+
+	verifyOfx(t, _ofx, "098-121", "987654321")
+}
+
+func TestParseHeaderV2(t *testing.T) {
+	_ofx := parseFile(t, "testdata/sgml.xml")
+
+	expected := Header{
+		OFXHeader:  "200",
+		Version:    "211",
+		Security:   "NONE",
+		Encoding:   "UTF-8",
+		OldFileUID: "NONE",
+		NewFileUID: "NONE",
+	}
+	if _ofx.Header != expected {
+		t.Errorf("Wrong header. Expected: %+v Actual: %+v\n", expected, _ofx.Header)
+	}
+
+	verifyOfx(t, _ofx, "000012345678", "121000358")
+}
+
+func TestParseV2Namespaced(t *testing.T) {
+	v1 := parseFile(t, "testdata/v103.ofx")
+	v2 := parseFile(t, "testdata/v211.xml")
+
+	if v2.Header.OFXHeader != "200" || v2.Header.Version != "211" || v2.Header.Encoding != "UTF-8" {
+		t.Errorf("Wrong header. Actual: %+v\n", v2.Header)
+	}
+
+	// Apart from the header, the 2.x document is the same statement.
+	v1.Header, v2.Header = Header{}, Header{}
+	if !reflect.DeepEqual(v1, v2) {
+		t.Errorf("Wrong statement.\nExpected: %s\nActual:   %s\n", v1, v2)
+	}
+}
+
+func TestParseProcInstAfterDoctype(t *testing.T) {
+	in := `<!DOCTYPE OFX><?OFX OFXHEADER="200" VERSION="220"?><OFX><BANKACCTFROM><ACCTID>1</ACCTID></BANKACCTFROM></OFX>`
+
+	_ofx, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _ofx.Header.OFXHeader != "200" || _ofx.Header.Version != "220" {
+		t.Errorf("Wrong header. Actual: %+v\n", _ofx.Header)
+	}
+	if _ofx.AccountNumber != "1" {
+		t.Errorf("Wrong account number. Expected: 1 Actual: %s\n", _ofx.AccountNumber)
+	}
+}
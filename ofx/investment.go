@@ -0,0 +1,36 @@
+package ofx
+
+import (
+	"fmt"
+	"time"
+)
+
+// AccountTypeInvestment is the AccountType of brokerage statements read
+// from <INVACCTFROM>.
+const AccountTypeInvestment = "INVESTMENT"
+
+// InvestmentTransaction is a single buy, sell, reinvestment or income entry
+// of an investment statement. Kind holds the name of the OFX aggregate, e.g.
+// BUYSTOCK. SecurityName and Ticker are those of the security in the
+// <SECLIST> of the download, if any.
+type InvestmentTransaction struct {
+	Kind           string    `json:"kind"`
+	FitID          string    `json:"fit_id"`
+	TradeDateTime  time.Time `json:"trade_datetime"`
+	SettleDateTime time.Time `json:"settle_datetime"`
+	Memo           string    `json:"memo"`
+	SecurityID     string    `json:"security_id"`
+	SecurityIDType string    `json:"security_id_type"`
+	SecurityName   string    `json:"security_name,omitempty"`
+	Ticker         string    `json:"ticker,omitempty"`
+	Units          float64   `json:"units"`
+	UnitPrice      float64   `json:"unit_price"`
+	Commission     Decimal   `json:"commission"`
+	Total          Decimal   `json:"total"`
+}
+
+func (t InvestmentTransaction) String() string {
+	return fmt.Sprintf("FitID:%-15s Kind:%-10s Trade:%s Security:%s Units:%g Price:%g Total: $%8s Memo:%s\n",
+		t.FitID, t.Kind, t.TradeDateTime.Format("2006/01/02"), t.SecurityID, t.Units, t.UnitPrice, t.Total, t.Memo,
+	)
+}
@@ -0,0 +1,89 @@
+package ofx
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseInvestment(t *testing.T) {
+	_ofx := parseFile(t, "testdata/investment.ofx")
+
+	verifyOfx(t, _ofx, "12345678", "")
+	if _ofx.AccountType != AccountTypeInvestment || _ofx.BrokerID != "example.com" {
+		t.Errorf("Wrong account. Expected: %s example.com Actual: %s %s\n", AccountTypeInvestment, _ofx.AccountType, _ofx.BrokerID)
+	}
+
+	if len(_ofx.Transactions) != 1 || _ofx.Transactions[0].FitID != "23324" {
+		t.Errorf("Expected the INVBANKTRAN deposit as a bank transaction. Actual: %v\n", _ofx.Transactions)
+	}
+
+	expected := []InvestmentTransaction{
+		{
+			Kind:           "BUYSTOCK",
+			FitID:          "23321",
+			TradeDateTime:  time.Date(2014, 6, 5, 0, 0, 0, 0, time.UTC),
+			SettleDateTime: time.Date(2014, 6, 8, 0, 0, 0, 0, time.UTC),
+			Memo:           "BUY ACME",
+			SecurityID:     "123456789",
+			SecurityIDType: "CUSIP",
+			Units:          100,
+			UnitPrice:      50.0025,
+			Commission:     NewDecial("9.95"),
+			Total:          NewDecial("-5010.20"),
+		},
+		{
+			Kind:           "SELLMF",
+			FitID:          "23322",
+			TradeDateTime:  time.Date(2014, 6, 12, 0, 0, 0, 0, time.UTC),
+			SettleDateTime: time.Date(2014, 6, 13, 0, 0, 0, 0, time.UTC),
+			SecurityID:     "922908363",
+			SecurityIDType: "CUSIP",
+			Units:          -12.5,
+			UnitPrice:      198.44,
+			Commission:     NewDecial("0"),
+			Total:          NewDecial("2480.50"),
+		},
+		{
+			Kind:           "INCOME",
+			FitID:          "23323",
+			TradeDateTime:  time.Date(2014, 6, 20, 0, 0, 0, 0, time.UTC),
+			Memo:           "DIVIDEND",
+			SecurityID:     "123456789",
+			SecurityIDType: "CUSIP",
+			Total:          NewDecial("42.00"),
+		},
+	}
+
+	if len(_ofx.InvestmentTransactions) != len(expected) {
+		t.Fatalf("Wrong number of investment transactions. Expected: %d Actual: %d\n", len(expected), len(_ofx.InvestmentTransactions))
+	}
+	for i, e := range expected {
+		if actual := *_ofx.InvestmentTransactions[i]; actual != e {
+			t.Errorf("Wrong investment transaction.\nExpected: %+v\nActual:   %+v\n", e, actual)
+		}
+	}
+}
+
+func TestParseSecurityList(t *testing.T) {
+	_ofx := parseFile(t, "testdata/seclist.ofx")
+
+	expected := map[string]Security{
+		"123456789": {Kind: "STOCKINFO", UniqueID: "123456789", UniqueIDType: "CUSIP", Name: "ACME CORP", Ticker: "ACME", UnitPrice: 51.25},
+		"922908363": {Kind: "MFINFO", UniqueID: "922908363", UniqueIDType: "CUSIP", Name: "VANGUARD 500 INDEX FUND", Ticker: "VFINX"},
+		"000000001": {Kind: "OPTINFO", UniqueID: "000000001", UniqueIDType: "CUSIP", Name: "ACME JAN 55 CALL"},
+	}
+	if !reflect.DeepEqual(_ofx.Securities, expected) {
+		t.Errorf("Wrong securities.\nExpected: %+v\nActual:   %+v\n", expected, _ofx.Securities)
+	}
+
+	names := []string{"ACME CORP/ACME", "VANGUARD 500 INDEX FUND/VFINX", "ACME CORP/ACME"}
+	for i, trans := range _ofx.InvestmentTransactions {
+		if actual := trans.SecurityName + "/" + trans.Ticker; actual != names[i] {
+			t.Errorf("Wrong security of %s. Expected: %s Actual: %s\n", trans.FitID, names[i], actual)
+		}
+	}
+	if _ofx.Warnings != nil {
+		t.Errorf("Expected no warnings. Actual: %q\n", _ofx.Warnings)
+	}
+}
@@ -0,0 +1,266 @@
+package ofx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// DateFormat selects how datetimes are written when a statement is
+// marshalled to JSON.
+type DateFormat int
+
+const (
+	// DateRFC3339 writes datetimes as RFC 3339 strings with their UTC
+	// offset, as time.Time does. It is the only format that keeps the
+	// instant and offset, losing only zone names such as PST, and zero
+	// datetimes are written as "0001-01-01T00:00:00Z".
+	DateRFC3339 DateFormat = iota
+
+	// DateOnly writes the YYYY-MM-DD date in the datetime's own zone, which
+	// is the posting date the bank reported. The time of day and offset are
+	// dropped, so two datetimes on either side of midnight UTC may not sort
+	// as they would in UTC. Zero datetimes are written as null.
+	DateOnly
+
+	// DateUnix writes datetimes as integer seconds since the Unix epoch.
+	// This is unambiguous and easy to compare, but drops the offset the bank
+	// reported and any fractional seconds. Zero datetimes are written as
+	// null.
+	DateUnix
+)
+
+// JSONDateFormat is the DateFormat used when marshalling statements,
+// transactions, investment transactions and named balances to JSON. It
+// applies to the whole program, so set it once before encoding.
+var JSONDateFormat = DateRFC3339
+
+// WriteJSON writes the statement to w as a single line of JSON, in the
+// JSONDateFormat, followed by a newline.
+func (o *Ofx) WriteJSON(w io.Writer) error {
+	res, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(res, '\n'))
+	return err
+}
+
+// jsonTime marshals a datetime in the JSONDateFormat.
+type jsonTime time.Time
+
+func (t jsonTime) MarshalJSON() ([]byte, error) {
+	tm := time.Time(t)
+	switch JSONDateFormat {
+	case DateOnly:
+		if tm.IsZero() {
+			return []byte("null"), nil
+		}
+		return []byte(`"` + tm.Format("2006-01-02") + `"`), nil
+	case DateUnix:
+		if tm.IsZero() {
+			return []byte("null"), nil
+		}
+		return []byte(strconv.FormatInt(tm.Unix(), 10)), nil
+	}
+	return tm.MarshalJSON()
+}
+
+// ReadJSON reads a statement written by WriteJSON, or any JSON object of
+// the same shape, from r. Datetimes may be in any DateFormat. Those written
+// in DateRFC3339 come back as the same instant and UTC offset, in a
+// location without a zone name, or UTC for a zero offset: see
+// canonicalZone.
+func ReadJSON(r io.Reader) (*Ofx, error) {
+	var o Ofx
+	if err := json.NewDecoder(r).Decode(&o); err != nil {
+		return nil, fmt.Errorf("Invalid statement json: %w", err)
+	}
+	return &o, nil
+}
+
+// canonicalZone returns t in the location a JSON datetime with its UTC
+// offset is read into: UTC for a zero offset, and otherwise an unnamed
+// fixed zone.
+func canonicalZone(t time.Time) time.Time {
+	_, offset := t.Zone()
+	if offset == 0 {
+		return t.UTC()
+	}
+	return t.In(fixedZone("", offset))
+}
+
+// UnmarshalJSON reads a datetime in any DateFormat: an RFC 3339 string, a
+// YYYY-MM-DD date, integer seconds since the Unix epoch, or null for the
+// zero time.
+func (t *jsonTime) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	switch {
+	case s == "null":
+		*t = jsonTime{}
+		return nil
+	case len(s) == len(`"2006-01-02"`) && s[0] == '"':
+		tm, err := time.Parse(`"2006-01-02"`, s)
+		if err != nil {
+			return fmt.Errorf("Invalid datetime json: %s", s)
+		}
+		*t = jsonTime(tm)
+		return nil
+	case len(s) > 0 && s[0] == '"':
+		var tm time.Time
+		if err := tm.UnmarshalJSON(b); err != nil {
+			return fmt.Errorf("Invalid datetime json: %s", s)
+		}
+		*t = jsonTime(canonicalZone(tm))
+		return nil
+	}
+
+	secs, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid datetime json: %s", s)
+	}
+	*t = jsonTime(time.Unix(secs, 0).UTC())
+	return nil
+}
+
+// The MarshalJSON methods below shadow each datetime field of the plain
+// struct with a jsonTime carrying the same JSON name. The default format
+// marshals the plain struct, keeping the field order of the declaration.
+
+func (t OfxTransaction) MarshalJSON() ([]byte, error) {
+	type transaction OfxTransaction
+	if JSONDateFormat == DateRFC3339 {
+		return json.Marshal(transaction(t))
+	}
+	return json.Marshal(struct {
+		transaction
+		PostedDateTime jsonTime `json:"posted_datetime"`
+		UserDateTime   jsonTime `json:"user_datetime"`
+	}{
+		transaction(t),
+		jsonTime(t.PostedDateTime),
+		jsonTime(t.UserDateTime),
+	})
+}
+
+func (t InvestmentTransaction) MarshalJSON() ([]byte, error) {
+	type investmentTransaction InvestmentTransaction
+	if JSONDateFormat == DateRFC3339 {
+		return json.Marshal(investmentTransaction(t))
+	}
+	return json.Marshal(struct {
+		investmentTransaction
+		TradeDateTime  jsonTime `json:"trade_datetime"`
+		SettleDateTime jsonTime `json:"settle_datetime"`
+	}{
+		investmentTransaction(t),
+		jsonTime(t.TradeDateTime),
+		jsonTime(t.SettleDateTime),
+	})
+}
+
+func (b NamedBalance) MarshalJSON() ([]byte, error) {
+	type namedBalance NamedBalance
+	if JSONDateFormat == DateRFC3339 {
+		return json.Marshal(namedBalance(b))
+	}
+	return json.Marshal(struct {
+		namedBalance
+		AsOfDateTime jsonTime `json:"as_of_datetime"`
+	}{
+		namedBalance(b),
+		jsonTime(b.AsOfDateTime),
+	})
+}
+
+func (o Ofx) MarshalJSON() ([]byte, error) {
+	type statement Ofx
+	if JSONDateFormat == DateRFC3339 {
+		return json.Marshal(statement(o))
+	}
+	return json.Marshal(struct {
+		statement
+		GeneratedDateTime        jsonTime `json:"generated_datetime"`
+		ProfileUpdatedDateTime   jsonTime `json:"profile_updated_datetime"`
+		AccountUpdatedDateTime   jsonTime `json:"account_updated_datetime"`
+		LedgerBalanceDate        jsonTime `json:"ledger_balance_date"`
+		AvailableBalanceDate     jsonTime `json:"available_balance_date"`
+		TransactionStartDateTime jsonTime `json:"transaction_start_datetime"`
+		TransactionEndDateTime   jsonTime `json:"transaction_end_datetime"`
+	}{
+		statement(o),
+		jsonTime(o.GeneratedDateTime),
+		jsonTime(o.ProfileUpdatedDateTime),
+		jsonTime(o.AccountUpdatedDateTime),
+		jsonTime(o.LedgerBalanceDate),
+		jsonTime(o.AvailableBalanceDate),
+		jsonTime(o.TransactionStartDateTime),
+		jsonTime(o.TransactionEndDateTime),
+	})
+}
+
+// The UnmarshalJSON methods below decode the plain struct, with each
+// datetime field shadowed by a *jsonTime pointing at it so that every
+// DateFormat can be read back.
+
+func (t *OfxTransaction) UnmarshalJSON(b []byte) error {
+	type transaction OfxTransaction
+	return json.Unmarshal(b, &struct {
+		*transaction
+		PostedDateTime *jsonTime `json:"posted_datetime"`
+		UserDateTime   *jsonTime `json:"user_datetime"`
+	}{
+		(*transaction)(t),
+		(*jsonTime)(&t.PostedDateTime),
+		(*jsonTime)(&t.UserDateTime),
+	})
+}
+
+func (t *InvestmentTransaction) UnmarshalJSON(b []byte) error {
+	type investmentTransaction InvestmentTransaction
+	return json.Unmarshal(b, &struct {
+		*investmentTransaction
+		TradeDateTime  *jsonTime `json:"trade_datetime"`
+		SettleDateTime *jsonTime `json:"settle_datetime"`
+	}{
+		(*investmentTransaction)(t),
+		(*jsonTime)(&t.TradeDateTime),
+		(*jsonTime)(&t.SettleDateTime),
+	})
+}
+
+func (b *NamedBalance) UnmarshalJSON(data []byte) error {
+	type namedBalance NamedBalance
+	return json.Unmarshal(data, &struct {
+		*namedBalance
+		AsOfDateTime *jsonTime `json:"as_of_datetime"`
+	}{
+		(*namedBalance)(b),
+		(*jsonTime)(&b.AsOfDateTime),
+	})
+}
+
+func (o *Ofx) UnmarshalJSON(b []byte) error {
+	type statement Ofx
+	return json.Unmarshal(b, &struct {
+		*statement
+		GeneratedDateTime        *jsonTime `json:"generated_datetime"`
+		ProfileUpdatedDateTime   *jsonTime `json:"profile_updated_datetime"`
+		AccountUpdatedDateTime   *jsonTime `json:"account_updated_datetime"`
+		LedgerBalanceDate        *jsonTime `json:"ledger_balance_date"`
+		AvailableBalanceDate     *jsonTime `json:"available_balance_date"`
+		TransactionStartDateTime *jsonTime `json:"transaction_start_datetime"`
+		TransactionEndDateTime   *jsonTime `json:"transaction_end_datetime"`
+	}{
+		(*statement)(o),
+		(*jsonTime)(&o.GeneratedDateTime),
+		(*jsonTime)(&o.ProfileUpdatedDateTime),
+		(*jsonTime)(&o.AccountUpdatedDateTime),
+		(*jsonTime)(&o.LedgerBalanceDate),
+		(*jsonTime)(&o.AvailableBalanceDate),
+		(*jsonTime)(&o.TransactionStartDateTime),
+		(*jsonTime)(&o.TransactionEndDateTime),
+	})
+}
@@ -0,0 +1,191 @@
+package ofx
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarshalJSONDateFormats(t *testing.T) {
+	_ofx := parseFile(t, "testdata/v103.ofx")
+	defer func(f DateFormat) { JSONDateFormat = f }(JSONDateFormat)
+
+	tests := []struct {
+		format    DateFormat
+		posted    string
+		generated string
+		zero      string
+	}{
+		{DateRFC3339, `"2007-03-15T00:00:00Z"`, `"2007-10-15T02:15:29-08:00"`, `"0001-01-01T00:00:00Z"`},
+		{DateOnly, `"2007-03-15"`, `"2007-10-15"`, `null`},
+		{DateUnix, `1173916800`, `1192443329`, `null`},
+	}
+	for _, test := range tests {
+		JSONDateFormat = test.format
+
+		res, err := json.Marshal(_ofx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var statement struct {
+			GeneratedDateTime json.RawMessage `json:"generated_datetime"`
+			Transactions      []struct {
+				PostedDateTime json.RawMessage `json:"posted_datetime"`
+			} `json:"transactions"`
+		}
+		if err := json.Unmarshal(res, &statement); err != nil {
+			t.Fatal(err)
+		}
+
+		if actual := string(statement.GeneratedDateTime); actual != test.generated {
+			t.Errorf("Wrong generated datetime for format %d. Expected: %s Actual: %s\n", test.format, test.generated, actual)
+		}
+		if actual := string(statement.Transactions[0].PostedDateTime); actual != test.posted {
+			t.Errorf("Wrong posted datetime for format %d. Expected: %s Actual: %s\n", test.format, test.posted, actual)
+		}
+
+		var zero struct {
+			UserDateTime json.RawMessage `json:"user_datetime"`
+		}
+		res, err = json.Marshal(&OfxTransaction{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(res, &zero); err != nil {
+			t.Fatal(err)
+		}
+		if actual := string(zero.UserDateTime); actual != test.zero {
+			t.Errorf("Wrong zero datetime for format %d. Expected: %s Actual: %s\n", test.format, test.zero, actual)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	_ofx := parseFile(t, "testdata/v103.ofx")
+
+	var buf bytes.Buffer
+	if err := _ofx.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := json.Marshal(_ofx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := buf.String(); actual != string(expected)+"\n" {
+		t.Errorf("Wrong json. Expected: %s Actual: %s\n", expected, actual)
+	}
+
+	var decoded Ofx
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Invalid json: %v\n", err)
+	}
+	if decoded.AccountNumber != _ofx.AccountNumber || len(decoded.Transactions) != len(_ofx.Transactions) {
+		t.Errorf("Wrong decoded statement. Expected: %s Actual: %s\n", _ofx, decoded)
+	}
+}
+
+func TestReadJSONRoundTrip(t *testing.T) {
+	names, err := filepath.Glob("testdata/*.ofx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names = append(names, "testdata/pending.xml")
+
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := Parse(f)
+		f.Close()
+		if err != nil {
+			// Fixtures of errors and non-statements.
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := expected.WriteJSON(&buf); err != nil {
+			t.Fatalf("%s: %v\n", name, err)
+		}
+		actual, err := ReadJSON(&buf)
+		if err != nil {
+			t.Fatalf("%s: failed to read written json: %v\n", name, err)
+		}
+
+		// RFC 3339 keeps the offset of each datetime but not its zone name.
+		canonicalZones(reflect.ValueOf(expected))
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: round trip differs.\nExpected: %s\nActual:   %s\n", name, expected, actual)
+		}
+	}
+}
+
+// canonicalZones moves every datetime reachable from v into its
+// canonicalZone.
+func canonicalZones(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			canonicalZones(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			canonicalZones(v.Index(i))
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			e := reflect.New(v.Type().Elem()).Elem()
+			e.Set(v.MapIndex(k))
+			canonicalZones(e)
+			v.SetMapIndex(k, e)
+		}
+	case reflect.Struct:
+		if tm, ok := v.Interface().(time.Time); ok {
+			v.Set(reflect.ValueOf(canonicalZone(tm)))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				canonicalZones(v.Field(i))
+			}
+		}
+	}
+}
+
+func TestReadJSONDateFormats(t *testing.T) {
+	defer func(f DateFormat) { JSONDateFormat = f }(JSONDateFormat)
+
+	expected := parseFile(t, "testdata/v103.ofx")
+	for _, format := range []DateFormat{DateOnly, DateUnix} {
+		JSONDateFormat = format
+		var buf bytes.Buffer
+		if err := expected.WriteJSON(&buf); err != nil {
+			t.Fatal(err)
+		}
+		actual, err := ReadJSON(&buf)
+		if err != nil {
+			t.Fatalf("Failed to read json in format %d: %v\n", format, err)
+		}
+
+		trans, got := expected.Transactions[0], actual.Transactions[0]
+		if format == DateOnly && got.PostedDateTime.Format("2006-01-02") != trans.PostedDateTime.Format("2006-01-02") {
+			t.Errorf("Wrong date. Expected: %s Actual: %s\n", trans.PostedDateTime, got.PostedDateTime)
+		}
+		if format == DateUnix && !got.PostedDateTime.Equal(trans.PostedDateTime) {
+			t.Errorf("Wrong datetime. Expected: %s Actual: %s\n", trans.PostedDateTime, got.PostedDateTime)
+		}
+		if got.Amount != trans.Amount || got.FitID != trans.FitID {
+			t.Errorf("Wrong transaction. Expected: %s Actual: %s\n", trans, got)
+		}
+	}
+
+	if _, err := ReadJSON(strings.NewReader(`{"generated_datetime": "yesterday"}`)); err == nil {
+		t.Errorf("Expected an error for an invalid datetime\n")
+	}
+}
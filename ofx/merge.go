@@ -0,0 +1,85 @@
+package ofx
+
+// Merge combines the statements of each account into a single statement,
+// such as consecutive monthly exports. Statements are grouped by account
+// type, bank id and account id, in order of first appearance. The merged
+// statement keeps the fields of the first statement of its account, with
+//   - the transactions of every statement, in order, dropping those whose
+//     FITID was already seen;
+//   - the earliest DTSTART and latest DTEND;
+//   - the most recent ledger and available balances, going by their DTASOF
+//     or, failing that, the order of the statements.
+//
+// The statements passed in are not modified. ParseDocument keeps repeated
+// statements for an account, such as a duplicated download, apart and warns
+// about them; Merge is how to combine them.
+func Merge(statements []*Ofx) []*Ofx {
+	var merged []*Ofx
+	byAccount := map[statementAccount]*Ofx{}
+	for _, s := range statements {
+		key := s.account()
+		m, ok := byAccount[key]
+		if !ok {
+			c := *s
+			c.Transactions = append([]*OfxTransaction{}, s.Transactions...)
+			c.InvestmentTransactions = append([]*InvestmentTransaction(nil), s.InvestmentTransactions...)
+			c.Balances = append([]NamedBalance(nil), s.Balances...)
+			byAccount[key] = &c
+			merged = append(merged, &c)
+			continue
+		}
+		m.merge(s)
+	}
+
+	for _, m := range merged {
+		m.dropRepeatedFitIDs()
+	}
+	return merged
+}
+
+// statementAccount identifies the account of a statement.
+type statementAccount struct{ accountType, bankID, acctID string }
+
+func (o *Ofx) account() statementAccount {
+	return statementAccount{o.AccountType, o.AccountBankNumber, o.AccountNumber}
+}
+
+func (o *Ofx) merge(s *Ofx) {
+	o.Transactions = append(o.Transactions, s.Transactions...)
+	o.InvestmentTransactions = append(o.InvestmentTransactions, s.InvestmentTransactions...)
+
+	if !s.TransactionStartDateTime.IsZero() && (o.TransactionStartDateTime.IsZero() || s.TransactionStartDateTime.Before(o.TransactionStartDateTime)) {
+		o.TransactionStartDateTime = s.TransactionStartDateTime
+	}
+	if s.TransactionEndDateTime.After(o.TransactionEndDateTime) {
+		o.TransactionEndDateTime = s.TransactionEndDateTime
+	}
+	if s.GeneratedDateTime.After(o.GeneratedDateTime) {
+		o.GeneratedDateTime = s.GeneratedDateTime
+	}
+
+	if !s.LedgerBalanceDate.Before(o.LedgerBalanceDate) {
+		o.LedgerBalance, o.LedgerBalanceDate = s.LedgerBalance, s.LedgerBalanceDate
+		if len(s.Balances) > 0 {
+			o.Balances = append([]NamedBalance(nil), s.Balances...)
+		}
+	}
+	if !s.AvailableBalanceDate.Before(o.AvailableBalanceDate) {
+		o.AvailableBalance, o.AvailableBalanceDate = s.AvailableBalance, s.AvailableBalanceDate
+	}
+}
+
+// dropRepeatedFitIDs removes every transaction whose FITID already appeared
+// earlier in the statement, like WithDedupe.
+func (o *Ofx) dropRepeatedFitIDs() {
+	seen := map[string]bool{}
+	kept := o.Transactions[:0]
+	for _, t := range o.Transactions {
+		if t.FitID != "" && seen[t.FitID] {
+			continue
+		}
+		seen[t.FitID] = true
+		kept = append(kept, t)
+	}
+	o.Transactions = kept
+}
@@ -0,0 +1,110 @@
+package ofx
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMerge(t *testing.T) {
+	var statements []*Ofx
+	for _, name := range []string{"testdata/monthly-jan.ofx", "testdata/monthly-feb.ofx", "testdata/monthly-mar.ofx"} {
+		statements = append(statements, parseFile(t, name))
+	}
+	other := parseFile(t, "testdata/v103.ofx")
+	statements = append(statements, other)
+
+	merged := Merge(statements)
+	if len(merged) != 2 {
+		t.Fatalf("Wrong number of merged statements. Expected: 2 Actual: %d\n", len(merged))
+	}
+	if merged[1] == other || !reflect.DeepEqual(merged[1], other) {
+		t.Errorf("Expected a copy of the lone statement.\n")
+	}
+
+	m := merged[0]
+	var fitIDs []string
+	for _, trans := range m.Transactions {
+		fitIDs = append(fitIDs, trans.FitID)
+	}
+	if expected := []string{"M1", "M2", "M3", "M4", "M5"}; !reflect.DeepEqual(fitIDs, expected) {
+		t.Errorf("Wrong merged transactions. Expected: %v Actual: %v\n", expected, fitIDs)
+	}
+
+	start, end := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC)
+	if !m.TransactionStartDateTime.Equal(start) || !m.TransactionEndDateTime.Equal(end) {
+		t.Errorf("Wrong merged period. Expected: %s - %s Actual: %s - %s\n", start, end, m.TransactionStartDateTime, m.TransactionEndDateTime)
+	}
+	if m.LedgerBalance.String() != "5054.90" {
+		t.Errorf("Wrong merged ledger balance. Expected: 5054.90 Actual: %s\n", m.LedgerBalance)
+	}
+
+	if len(statements[0].Transactions) != 2 {
+		t.Errorf("Merge modified its input. Actual: %d transactions\n", len(statements[0].Transactions))
+	}
+}
+
+func TestMergeRepeatedAccount(t *testing.T) {
+	f, err := os.Open("testdata/repeated.ofx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	doc, err := ParseDocument(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Parsing keeps both statements and flags the second.
+	if len(doc.Statements) != 2 {
+		t.Fatalf("Wrong number of statements. Expected: 2 Actual: %d\n", len(doc.Statements))
+	}
+	if doc.Statements[0].Warnings != nil {
+		t.Errorf("Expected no warnings on the first statement. Actual: %q\n", doc.Statements[0].Warnings)
+	}
+	if expected := []string{"Repeated statement for the account of statement 1"}; !reflect.DeepEqual(doc.Statements[1].Warnings, expected) {
+		t.Errorf("Wrong warnings. Expected: %q Actual: %q\n", expected, doc.Statements[1].Warnings)
+	}
+
+	// Merge combines them, keeping each FITID once.
+	merged := Merge(doc.Statements)
+	if len(merged) != 1 {
+		t.Fatalf("Wrong number of merged statements. Expected: 1 Actual: %d\n", len(merged))
+	}
+	var fitIDs []string
+	for _, trans := range merged[0].Transactions {
+		fitIDs = append(fitIDs, trans.FitID)
+	}
+	if expected := []string{"M1", "M2", "M3"}; !reflect.DeepEqual(fitIDs, expected) {
+		t.Errorf("Wrong merged transactions. Expected: %v Actual: %v\n", expected, fitIDs)
+	}
+	if merged[0].LedgerBalance.String() != "1934.90" {
+		t.Errorf("Wrong merged ledger balance. Expected: 1934.90 Actual: %s\n", merged[0].LedgerBalance)
+	}
+
+	strict, err := os.Open("testdata/repeated.ofx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer strict.Close()
+	if _, err := ParseDocument(strict, WithStrict()); err == nil || err.Error() != "Duplicate statement for account 7777" {
+		t.Errorf("Expected a duplicate statement error. Actual: %v\n", err)
+	}
+
+	// Every repeat is flagged, not only the first.
+	stmt := `<STMTTRNRS><STMTRS><BANKACCTFROM><ACCTID>7777</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM></STMTRS></STMTTRNRS>`
+	doc, err = ParseDocument(strings.NewReader("<OFX><BANKMSGSRSV1>" + stmt + stmt + stmt + "</BANKMSGSRSV1></OFX>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Statements) != 3 {
+		t.Fatalf("Wrong number of statements. Expected: 3 Actual: %d\n", len(doc.Statements))
+	}
+	for _, s := range doc.Statements[1:] {
+		if expected := []string{"Repeated statement for the account of statement 1"}; !reflect.DeepEqual(s.Warnings, expected) {
+			t.Errorf("Wrong warnings. Expected: %q Actual: %q\n", expected, s.Warnings)
+		}
+	}
+}
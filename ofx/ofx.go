@@ -0,0 +1,259 @@
+// Package ofx parses OFX (Open Financial Exchange) bank statements, in both
+// the OFX 1.x SGML and the OFX 2.x XML dialects.
+package ofx
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// OfxTransaction is a single <STMTTRN> entry of a statement.
+//
+// Currency and CurrencyRate are only set when the transaction carries its
+// own <CURRENCY> or <ORIGCURRENCY> block. A <CURRENCY> overrides the
+// statement currency: the amount is in Currency, and CurrencyRate converts
+// it to the statement currency. An <ORIGCURRENCY>, for which
+// OriginalCurrency is set, does not: the amount is already in the statement
+// currency, converted at CurrencyRate from an original amount in Currency.
+// Payee is only set when the transaction has a structured <PAYEE> in place
+// of a <NAME>, and SIC is the merchant's Standard Industrial Classification
+// code when the bank provides one. RawAmount, the <TRNAMT> exactly as
+// written, is only set when parsing WithRawAmounts, and RunningBalance is nil
+// unless set by ComputeRunningBalances.
+//
+// A transaction without a <FITID> is given a synthetic FitID, derived from
+// its date, amount, name and memo so that it is the same each time the file
+// is parsed, and SyntheticFitID is set. Identical transactions of a
+// statement are told apart by a -2, -3... suffix in file order.
+//
+// TransactionList counts the <BANKTRANLIST> holding the transaction from
+// zero, for the rare statements with more than one. DestinationAccount is
+// the <BANKACCTTO> or <CCACCTTO> of a transfer, the account the money moved
+// to. Category is only set when parsing WithCategorizer.
+type OfxTransaction struct {
+	FitID            string    `json:"fit_id"`
+	Type             string    `json:"type"`
+	PostedDateTime   time.Time `json:"posted_datetime"`
+	UserDateTime     time.Time `json:"user_datetime"`
+	Amount           Decimal   `json:"amount"`
+	RawAmount        string    `json:"raw_amount,omitempty"`
+	Currency         string    `json:"currency"`
+	CurrencyRate     float64   `json:"currency_rate"`
+	OriginalCurrency bool      `json:"original_currency,omitempty"`
+	CheckNum         string    `json:"check_num"`
+	Name             string    `json:"name"`
+	Memo             string    `json:"memo"`
+	SyntheticFitID   bool      `json:"synthetic_fit_id,omitempty"`
+	SIC              string    `json:"sic,omitempty"`
+	Payee            *Payee    `json:"payee,omitempty"`
+	RunningBalance   *Decimal  `json:"running_balance,omitempty"`
+
+	TransactionList int `json:"transaction_list,omitempty"`
+
+	DestinationAccount *Account `json:"destination_account,omitempty"`
+
+	Category string `json:"category,omitempty"`
+}
+
+// Payee is the <PAYEE> block of a transaction, identifying the merchant or
+// biller by name, address and phone number.
+type Payee struct {
+	Name       string `json:"name"`
+	Addr1      string `json:"addr1"`
+	Addr2      string `json:"addr2,omitempty"`
+	Addr3      string `json:"addr3,omitempty"`
+	City       string `json:"city"`
+	State      string `json:"state"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country,omitempty"`
+	Phone      string `json:"phone,omitempty"`
+}
+
+// Account is the other side of a transfer. AccountType is the <ACCTTYPE>
+// of a <BANKACCTTO>, or AccountTypeCreditCard for a <CCACCTTO>.
+type Account struct {
+	BankID      string `json:"bank_id,omitempty"`
+	BranchID    string `json:"branch_id,omitempty"`
+	AccountID   string `json:"account_id"`
+	AccountType string `json:"account_type"`
+}
+
+func (t OfxTransaction) String() string {
+	return fmt.Sprintf("FitID:%-15s Type:%-10s User:%s Amount: $%8s Check:%-6s Name:%s Memo:%s\n",
+		t.FitID, t.Type, t.PostedDateTime.Format("2006/01/02"), t.Amount, t.CheckNum, t.Name, t.Memo,
+	)
+}
+
+// Institution identifies the financial institution that produced a
+// statement, from the <FI> block of the signon response.
+type Institution struct {
+	Org string `json:"org"`
+	Fid string `json:"fid"`
+}
+
+// AccountTypeCreditCard is the AccountType of credit card statements, whose
+// <CCACCTFROM> carries no <ACCTTYPE> of its own.
+const AccountTypeCreditCard = "CREDITCARD"
+
+// NamedBalance is an entry of the <BALLIST> of a statement, carrying a
+// balance such as year-to-date interest beyond the ledger and available
+// balances. Type is DOLLAR, PERCENT or NUMBER, and Value keeps the number of
+// decimal places it was written with.
+type NamedBalance struct {
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	Type         string    `json:"type"`
+	Value        Decimal   `json:"value"`
+	AsOfDateTime time.Time `json:"as_of_datetime"`
+}
+
+// Ofx is a parsed OFX bank, credit card or investment statement.
+//
+// ProfileUpdatedDateTime and AccountUpdatedDateTime are the <DTPROFUP> and
+// <DTACCTUP> of the signon response: when the institution last changed its
+// profile and the list of accounts, so clients know when to fetch them again.
+type Ofx struct {
+	Header                   Header            `json:"header"`
+	Institution              Institution       `json:"institution"`
+	SignonStatus             Status            `json:"signon_status"`
+	GeneratedDateTime        time.Time         `json:"generated_datetime"`
+	Language                 string            `json:"language"`
+	ProfileUpdatedDateTime   time.Time         `json:"profile_updated_datetime"`
+	AccountUpdatedDateTime   time.Time         `json:"account_updated_datetime"`
+	AccountBankNumber        string            `json:"account_bank_number"`
+	BrokerID                 string            `json:"broker_id,omitempty"`
+	AccountNumber            string            `json:"account_number"`
+	AccountType              string            `json:"account_type"`
+	Status                   Status            `json:"status"`
+	Currency                 string            `json:"currency"`
+	LedgerBalance            Decimal           `json:"ledger_balance"`
+	LedgerBalanceDate        time.Time         `json:"ledger_balance_date"`
+	AvailableBalance         Decimal           `json:"available_balance"`
+	AvailableBalanceDate     time.Time         `json:"available_balance_date"`
+	Balances                 []NamedBalance    `json:"balances,omitempty"`
+	TransactionStartDateTime time.Time         `json:"transaction_start_datetime"`
+	TransactionEndDateTime   time.Time         `json:"transaction_end_datetime"`
+	Transactions             []*OfxTransaction `json:"transactions"`
+
+	// PendingTransactions are the <STMTTRNP> entries of the <BANKTRANLISTP>,
+	// which have not posted yet and may still change or disappear. They are
+	// not part of Transactions or the balances. Their PostedDateTime is the
+	// <DTTRAN> of the pending transaction.
+	PendingTransactions []*OfxTransaction `json:"pending_transactions,omitempty"`
+
+	InvestmentTransactions []*InvestmentTransaction `json:"investment_transactions,omitempty"`
+
+	// Securities holds the <SECLIST> of an investment download keyed by the
+	// <UNIQUEID> of each security. The list is shared by every investment
+	// statement of the download.
+	Securities map[string]Security `json:"securities,omitempty"`
+
+	// AggregatorBankID, AggregatorUserID, AggregatorAccountID and
+	// AccountNickname are the Intuit extension elements <INTU.BID>,
+	// <INTU.USERID>, <INTU.ACCTID> and <INTU.ACCTNICK> written by Quicken,
+	// Mint and other aggregators. Those of the signon response are shared
+	// by every statement that does not set its own.
+	AggregatorBankID    string `json:"aggregator_bank_id,omitempty"`
+	AggregatorUserID    string `json:"aggregator_user_id,omitempty"`
+	AggregatorAccountID string `json:"aggregator_account_id,omitempty"`
+	AccountNickname     string `json:"account_nickname,omitempty"`
+
+	// Extensions holds the values of other vendor extension elements, such
+	// as <INTU.XFERSRC>, and the marketing text of <MKTGINFO>, keyed by tag
+	// name. Those of the signon response are shared by every statement; if a
+	// tag appears more than once the last value wins.
+	Extensions map[string]string `json:"extensions,omitempty"`
+
+	// Warnings describes problems that did not stop parsing, such as
+	// unknown transaction elements or input that ends in malformed markup.
+	// Those outside of any statement are shared by every statement.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+func (o Ofx) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("Institution:%s/%s ", o.Institution.Org, o.Institution.Fid))
+	buf.WriteString(fmt.Sprintf("Generated:%s Lang:%s AccountBankNumber:%s AccountNumber:%s AccountType:%s\n",
+		o.GeneratedDateTime, o.Language, o.AccountBankNumber, o.AccountNumber, o.AccountType))
+	buf.WriteString(fmt.Sprintf("Ledger: $%s Av: $%s Start:%s End%s\n",
+		o.LedgerBalance, o.AvailableBalance, o.TransactionStartDateTime, o.TransactionEndDateTime))
+
+	for _, t := range o.Transactions {
+		buf.WriteString(fmt.Sprintf("%s", t))
+	}
+	for _, t := range o.InvestmentTransactions {
+		buf.WriteString(fmt.Sprintf("%s", t))
+	}
+
+	return buf.String()
+}
+
+// setSignon copies the fields taken from the signon response, which is
+// shared by every statement in a document.
+func (o *Ofx) setSignon(signon *Ofx) {
+	o.SignonStatus = signon.SignonStatus
+	o.Institution = signon.Institution
+	o.GeneratedDateTime = signon.GeneratedDateTime
+	o.Language = signon.Language
+	o.ProfileUpdatedDateTime = signon.ProfileUpdatedDateTime
+	o.AccountUpdatedDateTime = signon.AccountUpdatedDateTime
+
+	if len(signon.Warnings) > 0 {
+		o.Warnings = append(append([]string(nil), signon.Warnings...), o.Warnings...)
+	}
+
+	if o.AggregatorBankID == "" {
+		o.AggregatorBankID = signon.AggregatorBankID
+	}
+	if o.AggregatorUserID == "" {
+		o.AggregatorUserID = signon.AggregatorUserID
+	}
+	if o.AggregatorAccountID == "" {
+		o.AggregatorAccountID = signon.AggregatorAccountID
+	}
+	if o.AccountNickname == "" {
+		o.AccountNickname = signon.AccountNickname
+	}
+
+	if len(signon.Extensions) > 0 {
+		extensions := map[string]string{}
+		for k, v := range signon.Extensions {
+			extensions[k] = v
+		}
+		for k, v := range o.Extensions {
+			extensions[k] = v
+		}
+		o.Extensions = extensions
+	}
+}
+
+// reversedPeriod reports whether DTSTART is later than DTEND, as some banks
+// export.
+func (o *Ofx) reversedPeriod() bool {
+	return !o.TransactionEndDateTime.IsZero() && o.TransactionStartDateTime.After(o.TransactionEndDateTime)
+}
+
+// OfxDocument is a parsed OFX file, which may hold statements for several
+// accounts.
+type OfxDocument struct {
+	Header     Header `json:"header"`
+	Statements []*Ofx `json:"statements"`
+}
+
+// AllTransactions yields every transaction of every statement, in order,
+// together with the AccountNumber of its statement. It is meant to be ranged
+// over:
+//
+//	for acct, t := range doc.AllTransactions {
+//		...
+//	}
+func (d *OfxDocument) AllTransactions(yield func(acct string, t *OfxTransaction) bool) {
+	for _, s := range d.Statements {
+		for _, t := range s.Transactions {
+			if !yield(s.AccountNumber, t) {
+				return
+			}
+		}
+	}
+}
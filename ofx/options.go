@@ -0,0 +1,108 @@
+package ofx
+
+import (
+	"context"
+	"time"
+)
+
+// Option configures Parse, ParseDocument, ParseStream and ParseContext.
+type Option func(*options)
+
+type options struct {
+	dedupe   bool
+	strict   bool
+	rawAmts  bool
+	location *time.Location
+	excess   ExcessPlaces
+	// serverZone interprets datetimes without an offset in the zone of
+	// <DTSERVER>.
+	serverZone bool
+
+	// categorize, when set, gives each transaction its Category.
+	categorize Categorizer
+
+	// ctx, when set, is checked while parsing by ParseContext.
+	ctx context.Context
+
+	// unknownElements, when set, counts the unknown transaction elements
+	// for ParseFull.
+	unknownElements map[string]int
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithDedupe drops every transaction whose FITID was already seen earlier in
+// the same statement, preserving the order of the remaining ones. Banks that
+// emit overlapping statements repeat transactions this way, and the FITID is
+// the OFX-designated unique key of a transaction.
+func WithDedupe() Option {
+	return func(o *options) {
+		o.dedupe = true
+	}
+}
+
+// WithStrict fails parsing on the problems that are otherwise tolerated and
+// reported in Ofx.Warnings: unknown transaction elements, unexpected tokens
+// and malformed input.
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithRawAmounts keeps the text of each <TRNAMT> in OfxTransaction.RawAmount,
+// without the surrounding whitespace, so that it can be audited against the
+// Decimal it was parsed into. Digits beyond the places of the currency are
+// otherwise lost, unless parsing WithExcessPlaces.
+func WithRawAmounts() Option {
+	return func(o *options) {
+		o.rawAmts = true
+	}
+}
+
+// WithLocation interprets datetimes that carry no [offset:tz] suffix, such as
+// a bare 20230105, as local time in loc rather than UTC. Datetimes with an
+// offset keep it and are unaffected.
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.location = loc
+	}
+}
+
+// WithServerZone interprets datetimes that carry no [offset:tz] suffix in
+// the zone of the <DTSERVER> of the signon response, when that one carries
+// an offset, as in 20230105120000[-5:EST]. This is a heuristic: it assumes
+// the bank writes its local dates without an offset and stamps the server
+// time in the same zone, which holds for many banks but is not required by
+// OFX. Datetimes before <DTSERVER> and files whose <DTSERVER> has no offset
+// are unaffected, falling back to WithLocation or UTC.
+func WithServerZone() Option {
+	return func(o *options) {
+		o.serverZone = true
+	}
+}
+
+// WithExcessPlaces chooses how amounts written with more decimal places than
+// their currency has, such as a <TRNAMT> of 12.345 in USD, are parsed:
+// truncated, which is the default, rounded or kept in full. It applies to
+// transaction amounts, balances, and investment commissions and totals.
+func WithExcessPlaces(excess ExcessPlaces) Option {
+	return func(o *options) {
+		o.excess = excess
+	}
+}
+
+// WithCategorizer sets the Category of each transaction, posted or pending,
+// to what c returns for it once the transaction has been read. Transactions
+// are otherwise left uncategorized, as with NoCategory.
+func WithCategorizer(c Categorizer) Option {
+	return func(o *options) {
+		o.categorize = c
+	}
+}
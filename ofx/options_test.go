@@ -0,0 +1,290 @@
+package ofx
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseWithDedupe(t *testing.T) {
+	f, err := os.Open("testdata/duplicates.ofx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_ofx, err := Parse(f, WithDedupe())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fitIDs []string
+	for _, trans := range _ofx.Transactions {
+		fitIDs = append(fitIDs, trans.FitID)
+	}
+	if expected := []string{"D1", "D2", "D3"}; !reflect.DeepEqual(fitIDs, expected) {
+		t.Errorf("Wrong deduped transactions. Expected: %v Actual: %v\n", expected, fitIDs)
+	}
+
+	if all := parseFile(t, "testdata/duplicates.ofx"); len(all.Transactions) != 5 {
+		t.Errorf("Expected duplicates to be kept by default. Actual: %d\n", len(all.Transactions))
+	}
+}
+
+func TestParseStreamWithDedupe(t *testing.T) {
+	f, err := os.Open("testdata/duplicates.ofx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	count := 0
+	if _, err := ParseStream(f, func(*OfxTransaction) error { count++; return nil }, WithDedupe()); err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("Wrong number of streamed transactions. Expected: 3 Actual: %d\n", count)
+	}
+}
+
+func TestParseWithLocation(t *testing.T) {
+	sydney, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		t.Skipf("No time zone database: %v", err)
+	}
+
+	f, err := os.Open("testdata/balancedates.ofx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_ofx, err := Parse(f, WithLocation(sydney))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Midnight on 10 January 2007 in Sydney, during daylight saving (+11).
+	expected := time.Date(2007, 1, 9, 13, 0, 0, 0, time.UTC)
+	if posted := _ofx.Transactions[0].PostedDateTime; !posted.Equal(expected) || posted.Location() != sydney {
+		t.Errorf("Wrong posted datetime. Expected: %s Actual: %s\n", expected.In(sydney), posted)
+	}
+
+	// DTASOF carries its own [-5:EST] offset, which wins.
+	expected = time.Date(2007, 2, 1, 4, 59, 59, 0, time.UTC)
+	if asOf := _ofx.LedgerBalanceDate; !asOf.Equal(expected) {
+		t.Errorf("Wrong ledger balance date. Expected: %s Actual: %s\n", expected, asOf)
+	}
+}
+
+func TestParseWithServerZone(t *testing.T) {
+	f, err := os.Open("testdata/serverzone.ofx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_ofx, err := Parse(f, WithServerZone())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// DTPOSTED 20230103 has no offset, so takes the [-5:EST] of DTSERVER.
+	posted := _ofx.Transactions[0].PostedDateTime
+	if name, offset := posted.Zone(); name != "EST" || offset != -5*3600 {
+		t.Errorf("Wrong posted zone. Expected: EST -18000 Actual: %s %d\n", name, offset)
+	}
+	if expected := time.Date(2023, 1, 3, 5, 0, 0, 0, time.UTC); !posted.Equal(expected) {
+		t.Errorf("Wrong posted datetime. Expected: %s Actual: %s\n", expected, posted)
+	}
+	if end := _ofx.TransactionEndDateTime; end.Location() != posted.Location() {
+		t.Errorf("Wrong DTEND zone. Expected: %s Actual: %s\n", posted.Location(), end.Location())
+	}
+
+	// A datetime with its own offset keeps it.
+	if name, _ := _ofx.Transactions[1].PostedDateTime.Zone(); name != "CET" {
+		t.Errorf("Wrong zone of a datetime with an offset. Expected: CET Actual: %s\n", name)
+	}
+
+	// The heuristic is off by default, and needs a DTSERVER with an offset.
+	plain := parseFile(t, "testdata/serverzone.ofx")
+	if loc := plain.Transactions[0].PostedDateTime.Location(); loc != time.UTC {
+		t.Errorf("Expected UTC without WithServerZone. Actual: %s\n", loc)
+	}
+	g, err := os.Open("testdata/monthly-jan.ofx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	if unzoned, err := Parse(g, WithServerZone()); err != nil {
+		t.Fatal(err)
+	} else if loc := unzoned.Transactions[0].PostedDateTime.Location(); loc != time.UTC {
+		t.Errorf("Expected UTC for a DTSERVER without an offset. Actual: %s\n", loc)
+	}
+}
+
+func TestParseWithStrict(t *testing.T) {
+	for _, path := range []string{"testdata/unknown.ofx", "testdata/v103.ofx"} {
+		lenient := parseFile(t, path)
+
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		_, err = Parse(f, WithStrict())
+		switch {
+		case lenient.Warnings == nil && err != nil:
+			t.Errorf("Expected %s to parse strictly. Actual: %v\n", path, err)
+		case lenient.Warnings != nil && err == nil:
+			t.Errorf("Expected %s to fail strictly with one of %q\n", path, lenient.Warnings)
+		case err != nil && !strings.Contains(err.Error(), "<LOYALTYPOINTS>"):
+			t.Errorf("Expected the error to name the first problem. Actual: %v\n", err)
+		}
+	}
+}
+
+func TestParseWithRawAmounts(t *testing.T) {
+	const path = "testdata/rawamount.ofx"
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected []string
+	for _, line := range strings.Split(string(b), "\n") {
+		if s := strings.TrimSpace(line); strings.HasPrefix(s, "<TRNAMT>") {
+			expected = append(expected, strings.TrimPrefix(s, "<TRNAMT>"))
+		}
+	}
+
+	_ofx, err := Parse(bytes.NewReader(b), WithRawAmounts())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, trans := range _ofx.Transactions {
+		actual = append(actual, trans.RawAmount)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Wrong raw amounts. Expected: %q Actual: %q\n", expected, actual)
+	}
+	if amount := _ofx.Transactions[2].Amount.String(); amount != "-0.29" {
+		t.Errorf("Wrong amount. Expected: -0.29 Actual: %s\n", amount)
+	}
+
+	if plain := parseFile(t, path); plain.Transactions[0].RawAmount != "" {
+		t.Errorf("Expected no raw amount by default. Actual: %s\n", plain.Transactions[0].RawAmount)
+	}
+}
+
+func TestParseWithExcessPlaces(t *testing.T) {
+	tests := []struct {
+		opts     []Option
+		expected []string
+	}{
+		{nil, []string{"12.34", "-12.34", "1954.90"}},
+		{[]Option{WithExcessPlaces(TruncateExcessPlaces)}, []string{"12.34", "-12.34", "1954.90"}},
+		{[]Option{WithExcessPlaces(RoundExcessPlaces)}, []string{"12.35", "-12.35", "1954.91"}},
+		{[]Option{WithExcessPlaces(KeepExcessPlaces)}, []string{"12.345", "-12.345", "1954.905"}},
+	}
+
+	for i, test := range tests {
+		f, err := os.Open("testdata/excess.ofx")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ofx, err := Parse(f, test.opts...)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := []string{_ofx.Transactions[0].Amount.String(), _ofx.Transactions[1].Amount.String(), _ofx.LedgerBalance.String()}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("Wrong amounts for test %d. Expected: %v Actual: %v\n", i, test.expected, actual)
+		}
+	}
+}
+
+func TestParseWithCategorizer(t *testing.T) {
+	f, err := os.Open("testdata/quoting.ofx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var seen []string
+	categorize := func(trans *OfxTransaction) string {
+		seen = append(seen, trans.FitID)
+		if trans.Amount.Cents() > 0 {
+			return "Income"
+		}
+		if strings.Contains(trans.Memo, "Dinner") {
+			return "Dining"
+		}
+		return ""
+	}
+	_ofx, err := Parse(f, WithCategorizer(categorize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var categories []string
+	for _, trans := range _ofx.Transactions {
+		categories = append(categories, trans.Category)
+	}
+	if expected := []string{"Dining", "", "Income"}; !reflect.DeepEqual(categories, expected) {
+		t.Errorf("Wrong categories. Expected: %q Actual: %q\n", expected, categories)
+	}
+	if expected := []string{"Q1", "Q2", "Q3"}; !reflect.DeepEqual(seen, expected) {
+		t.Errorf("Wrong categorized transactions. Expected: %v Actual: %v\n", expected, seen)
+	}
+
+	for _, trans := range parseFile(t, "testdata/quoting.ofx").Transactions {
+		if trans.Category != "" {
+			t.Errorf("Expected no category by default. Actual: %s\n", trans.Category)
+		}
+	}
+}
+
+func TestKeywordCategorizer(t *testing.T) {
+	categorize := KeywordCategorizer(map[string]string{
+		"coffee":       "Coffee",
+		"COFFEE BEANS": "Groceries",
+		"station":      "Fuel",
+		"hold":         "Pending",
+	})
+
+	f, err := os.Open("testdata/pending.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	_ofx, err := Parse(f, WithCategorizer(categorize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	categories := map[string]string{}
+	for _, trans := range append(_ofx.Transactions, _ofx.PendingTransactions...) {
+		categories[trans.Name] = trans.Category
+	}
+	expected := map[string]string{"GAS STATION": "Fuel", "RESTAURANT": "Pending", "COFFEE": "Coffee"}
+	for name, category := range expected {
+		if categories[name] != category {
+			t.Errorf("Wrong category of %s. Expected: %s Actual: %s\n", name, category, categories[name])
+		}
+	}
+
+	if c := categorize(&OfxTransaction{Name: "Fresh Coffee Beans Co"}); c != "Groceries" {
+		t.Errorf("Expected the longest keyword to win. Actual: %s\n", c)
+	}
+	if c := NoCategory(&OfxTransaction{Name: "COFFEE"}); c != "" {
+		t.Errorf("Expected no category. Actual: %s\n", c)
+	}
+}
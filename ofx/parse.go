@@ -0,0 +1,1200 @@
+package ofx
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type nextKey int
+
+const (
+	none            nextKey = iota
+	acctID          nextKey = iota
+	acctType        nextKey = iota
+	curDef          nextKey = iota
+	branchID        nextKey = iota
+	bankID          nextKey = iota
+	transAmount     nextKey = iota
+	transDatePosted nextKey = iota
+	transUserDate   nextKey = iota
+	transFitID      nextKey = iota
+	transDesc       nextKey = iota
+	transMemo       nextKey = iota
+	transType       nextKey = iota
+	transCheckNum   nextKey = iota
+	legerBal        nextKey = iota
+	AvailBal        nextKey = iota
+	brokerID        nextKey = iota
+	invFitID        nextKey = iota
+	invDateTrade    nextKey = iota
+	invDateSettle   nextKey = iota
+	invMemo         nextKey = iota
+	invSecID        nextKey = iota
+	invSecIDType    nextKey = iota
+	invUnits        nextKey = iota
+	invUnitPrice    nextKey = iota
+	invCommission   nextKey = iota
+	invTotal        nextKey = iota
+	fiOrg           nextKey = iota
+	fiFid           nextKey = iota
+	dtServer        nextKey = iota
+	language        nextKey = iota
+	tranListStart   nextKey = iota
+	tranListEnd     nextKey = iota
+	transCurSym     nextKey = iota
+	transCurRate    nextKey = iota
+	legerBalDate    nextKey = iota
+	availBalDate    nextKey = iota
+	statusCode      nextKey = iota
+	statusSeverity  nextKey = iota
+	statusMessage   nextKey = iota
+	balName         nextKey = iota
+	balDesc         nextKey = iota
+	balType         nextKey = iota
+	balValue        nextKey = iota
+	balDate         nextKey = iota
+	transSIC        nextKey = iota
+	payeeName       nextKey = iota
+	payeeAddr1      nextKey = iota
+	payeeAddr2      nextKey = iota
+	payeeAddr3      nextKey = iota
+	payeeCity       nextKey = iota
+	payeeState      nextKey = iota
+	payeePostalCode nextKey = iota
+	payeeCountry    nextKey = iota
+	payeePhone      nextKey = iota
+	dtProfUp        nextKey = iota
+	dtAcctUp        nextKey = iota
+	secUniqueID     nextKey = iota
+	secUniqueIDType nextKey = iota
+	secName         nextKey = iota
+	secTicker       nextKey = iota
+	secUnitPrice    nextKey = iota
+	destBankID      nextKey = iota
+	destBranchID    nextKey = iota
+	destAcctID      nextKey = iota
+	destAcctType    nextKey = iota
+)
+
+// transactionKeys maps the leaf elements of a <STMTTRN> to the field they
+// populate.
+var transactionKeys = map[string]nextKey{
+	"DTPOSTED": transDatePosted,
+	"DTUSER":   transUserDate,
+	"TRNAMT":   transAmount,
+	"FITID":    transFitID,
+	"NAME":     transDesc,
+	"MEMO":     transMemo,
+	"TRNTYPE":  transType,
+	"DTTRAN":   transDatePosted,
+	"CHECKNUM": transCheckNum,
+	"SIC":      transSIC,
+}
+
+// ignoredTransactionElements lists the standard leaf elements of a <STMTTRN>
+// that are deliberately not read, so that they do not cause warnings.
+var ignoredTransactionElements = map[string]bool{
+	"DTAVAIL":       true,
+	"CORRECTFITID":  true,
+	"CORRECTACTION": true,
+	"SRVRTID":       true,
+	"REFNUM":        true,
+	"PAYEEID":       true,
+	"EXTDNAME":      true,
+	"INV401KSOURCE": true,
+	"DTEXPIRE":      true,
+}
+
+// notificationElements lists the standard leaf elements carrying marketing
+// or notification text for the user, such as the <MKTGINFO> of a statement.
+// They are kept in Extensions like vendor extensions.
+var notificationElements = map[string]bool{
+	"MKTGINFO": true,
+}
+
+// payeeKeys maps the leaf elements of a <PAYEE> to the field they populate.
+var payeeKeys = map[string]nextKey{
+	"NAME":       payeeName,
+	"ADDR1":      payeeAddr1,
+	"ADDR2":      payeeAddr2,
+	"ADDR3":      payeeAddr3,
+	"CITY":       payeeCity,
+	"STATE":      payeeState,
+	"POSTALCODE": payeePostalCode,
+	"COUNTRY":    payeeCountry,
+	"PHONE":      payeePhone,
+}
+
+// investmentKeys maps investment transaction leaf elements to the field they
+// populate.
+var investmentKeys = map[string]nextKey{
+	"FITID":        invFitID,
+	"MEMO":         invMemo,
+	"DTTRADE":      invDateTrade,
+	"DTSETTLE":     invDateSettle,
+	"UNIQUEID":     invSecID,
+	"UNIQUEIDTYPE": invSecIDType,
+	"UNITS":        invUnits,
+	"UNITPRICE":    invUnitPrice,
+	"COMMISSION":   invCommission,
+	"TOTAL":        invTotal,
+}
+
+// securityKeys maps the leaf elements of the <SECINFO> of a <SECLIST> entry,
+// and of its <SECID>, to the field they populate.
+var securityKeys = map[string]nextKey{
+	"SECID/UNIQUEID":     secUniqueID,
+	"SECID/UNIQUEIDTYPE": secUniqueIDType,
+	"SECINFO/SECNAME":    secName,
+	"SECINFO/TICKER":     secTicker,
+	"SECINFO/UNITPRICE":  secUnitPrice,
+}
+
+// transferResponses lists the transfer responses of the bank message set,
+// which sit alongside statements but carry none of their data. Their
+// account blocks in particular must not be taken for the statement account,
+// so they are skipped as a whole.
+var transferResponses = map[string]bool{
+	"INTRATRNRS":     true,
+	"INTERTRNRS":     true,
+	"WIRETRNRS":      true,
+	"RECINTRATRNRS":  true,
+	"RECINTERTRNRS":  true,
+	"INTRASYNCRS":    true,
+	"INTERSYNCRS":    true,
+	"WIRESYNCRS":     true,
+	"RECINTRASYNCRS": true,
+	"RECINTERSYNCRS": true,
+}
+
+// statusKeys maps the leaf elements of a <STATUS> to the field they populate.
+var statusKeys = map[string]nextKey{
+	"CODE":     statusCode,
+	"SEVERITY": statusSeverity,
+	"MESSAGE":  statusMessage,
+}
+
+// balanceKeys maps the leaf elements of the balance aggregates, keyed by
+// parent and element name, to the field they populate. <BAL> is an entry of
+// a <BALLIST>.
+var balanceKeys = map[string]nextKey{
+	"LEDGERBAL/BALAMT": legerBal,
+	"LEDGERBAL/DTASOF": legerBalDate,
+	"AVAILBAL/BALAMT":  AvailBal,
+	"AVAILBAL/DTASOF":  availBalDate,
+	"BAL/NAME":         balName,
+	"BAL/DESC":         balDesc,
+	"BAL/BALTYPE":      balType,
+	"BAL/VALUE":        balValue,
+	"BAL/DTASOF":       balDate,
+}
+
+// Parse reads an OFX document from f, including its header, and returns the
+// first statement it contains. Use ParseDocument for files that may hold
+// statements for several accounts.
+func Parse(f io.Reader, opts ...Option) (*Ofx, error) {
+	doc, err := ParseDocument(f, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Statements[0], nil
+}
+
+// ParseDocument reads an OFX document from f, including its header, and
+// transparently decompresses gzip input. Each <STMTTRNRS>, <CCSTMTTRNRS> or
+// <INVSTMTTRNRS> block becomes its own statement, with its own account
+// fields and transactions. The returned document always holds at least one
+// statement.
+func ParseDocument(f io.Reader, opts ...Option) (*OfxDocument, error) {
+	return parseDocument(f, nil, newOptions(opts))
+}
+
+// ParseStream is like Parse, but rather than collecting the transactions
+// into the statement it passes each one to onTransaction as soon as its
+// </STMTTRN> is read, so memory use does not grow with the number of
+// transactions. The returned statement carries everything else, and parsing
+// stops with the error returned by onTransaction, if any.
+func ParseStream(f io.Reader, onTransaction func(*OfxTransaction) error, opts ...Option) (*Ofx, error) {
+	doc, err := parseDocument(f, onTransaction, newOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return doc.Statements[0], nil
+}
+
+// ParseContext is like Parse, but gives up with the context's error once ctx
+// is cancelled or its deadline passes, bounding the time spent on huge or
+// slow inputs such as untrusted uploads.
+func ParseContext(ctx context.Context, f io.Reader, opts ...Option) (*Ofx, error) {
+	o := newOptions(opts)
+	o.ctx = ctx
+	doc, err := parseDocument(&contextReader{ctx: ctx, r: f}, nil, o)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Statements[0], nil
+}
+
+// contextReader fails reads once its context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// contextCheckInterval is the number of tokens read between checks of the
+// context passed to ParseContext.
+const contextCheckInterval = 256
+
+func parseDocument(f io.Reader, onTransaction func(*OfxTransaction) error, opts options) (*OfxDocument, error) {
+	doc := &OfxDocument{}
+	signon := &Ofx{}
+	var ofx *Ofx = nil
+	var seenFitIDs map[string]bool
+	var syntheticFitIDs map[string]int
+	current := func() *Ofx {
+		if ofx == nil {
+			ofx = &Ofx{Header: doc.Header, Transactions: []*OfxTransaction{}}
+			doc.Statements = append(doc.Statements, ofx)
+			seenFitIDs = map[string]bool{}
+			syntheticFitIDs = map[string]int{}
+		}
+		return ofx
+	}
+
+	stack := make([]string, 0, 64)
+	stackPos := 0
+
+	next := none
+	var trans *OfxTransaction = nil
+	var invTrans *InvestmentTransaction = nil
+	var sec *Security
+	securities := map[string]Security{}
+	var transErr error
+	var status *Status
+	var bal *NamedBalance
+	tranList := 0
+	// listTags counts the <STMTTRN> opened in the current <BANKTRANLIST>,
+	// and listRead the transactions read from them, while one is open.
+	listTags, listRead := -1, 0
+	tranLists := map[*Ofx]int{}
+	seenRoot := false
+	var otherMessageSet string
+	// skipFrom is the depth of the transfer response being skipped, if any.
+	skipFrom := 0
+
+	naive := time.UTC
+	if opts.location != nil {
+		naive = opts.location
+	}
+
+	// warn records a problem that did not stop parsing on the current
+	// statement, or on every statement when outside of one. In strict mode
+	// it returns the problem as an error instead. Each message is recorded
+	// once per statement. Messages leave out account numbers and FITIDs, so
+	// that masking a statement's fields masks them everywhere.
+	warned := map[*Ofx]map[string]bool{}
+	warn := func(msg, problem string) error {
+		if opts.strict {
+			return errors.New(problem)
+		}
+		target := signon
+		if ofx != nil {
+			target = ofx
+		}
+		if warned[target] == nil {
+			warned[target] = map[string]bool{}
+		}
+		if warned[target][msg] {
+			return nil
+		}
+		warned[target][msg] = true
+		target.Warnings = append(target.Warnings, msg)
+		return nil
+	}
+
+	// addTransaction adds trans, once its <STMTTRN> or <STMTTRNP> is
+	// closed, to its statement or passes it to onTransaction.
+	addTransaction := func(pending bool) error {
+		if pending {
+			if transErr != nil {
+				return fmt.Errorf("Failed to parse pending transaction FITID '%s': %w", trans.FitID, transErr)
+			}
+			if opts.categorize != nil {
+				trans.Category = opts.categorize(trans)
+			}
+			current().PendingTransactions = append(current().PendingTransactions, trans)
+			trans = nil
+			return nil
+		}
+
+		if transErr != nil {
+			return fmt.Errorf("Failed to parse transaction FITID '%s': %w", trans.FitID, transErr)
+		}
+		current()
+		listRead++
+		if trans.FitID == "" {
+			id := syntheticFitID(trans)
+			if syntheticFitIDs[id]++; syntheticFitIDs[id] > 1 {
+				id = fmt.Sprintf("%s-%d", id, syntheticFitIDs[id])
+			}
+			trans.FitID, trans.SyntheticFitID = id, true
+		}
+		if opts.categorize != nil {
+			trans.Category = opts.categorize(trans)
+		}
+		switch {
+		case opts.dedupe && seenFitIDs[trans.FitID]:
+			// Drop the repeated transaction.
+		case onTransaction != nil:
+			if err := onTransaction(trans); err != nil {
+				return err
+			}
+		default:
+			current().Transactions = append(current().Transactions, trans)
+		}
+		if opts.dedupe {
+			seenFitIDs[trans.FitID] = true
+		}
+		trans = nil
+		return nil
+	}
+
+	// checkList reports a <BANKTRANLIST> that opened more <STMTTRN> than
+	// transactions were read from, as when one is not closed before the
+	// next or the download was cut short.
+	checkList := func() error {
+		tags, read := listTags, listRead
+		listTags, listRead = -1, 0
+		if tags < 0 || tags == read {
+			return nil
+		}
+		return warn(fmt.Sprintf("Expected %d transactions in <BANKTRANLIST> but read %d", tags, read),
+			fmt.Sprintf("Transaction count mismatch in <BANKTRANLIST>: expected %d, read %d", tags, read))
+	}
+
+	br, err := maybeGunzip(bufio.NewReader(f))
+	if err != nil {
+		return nil, err
+	}
+	br, utf16, err := stripBOM(br)
+	if err != nil {
+		return nil, err
+	}
+	header, err := readHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(header); err != nil {
+		return nil, err
+	}
+	doc.Header = header
+
+	var body io.Reader = br
+	if !utf16 {
+		if body, err = decodeBody(br, header); err != nil {
+			return nil, err
+		}
+	}
+	dec := newSGMLDecoder(body)
+
+	tokens := 0
+	tok, err := dec.Token()
+	for err == nil {
+		if tokens++; opts.ctx != nil && tokens%contextCheckInterval == 0 {
+			if err := opts.ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack[:stackPos], t.Name.Local)
+			stackPos++
+
+			if stackPos == 1 {
+				if t.Name.Local != "OFX" {
+					return nil, fmt.Errorf("%w: root element <%s>", ErrNotOFX, t.Name.Local)
+				}
+				seenRoot = true
+			}
+
+			if skipFrom == 0 && transferResponses[t.Name.Local] {
+				skipFrom = stackPos
+			}
+			if skipFrom != 0 {
+				next = none
+				break
+			}
+
+			// Many element names are reused across aggregates, so leaf
+			// elements are only read in the parent they belong to.
+			parent := ""
+			if stackPos > 1 {
+				parent = stack[stackPos-2]
+			}
+			inTrans := trans != nil && (parent == "STMTTRN" || parent == "STMTTRNP")
+
+			switch t.Name.Local {
+			case "STMTTRNRS", "CCSTMTTRNRS", "INVSTMTTRNRS":
+				ofx = nil
+				current()
+
+			case "PROFMSGSRSV1", "SIGNUPMSGSRSV1":
+				if parent == "OFX" && otherMessageSet == "" {
+					otherMessageSet = t.Name.Local
+				}
+
+			case "STATUS":
+				switch parent {
+				case "SONRS":
+					status = &signon.SignonStatus
+				case "STMTTRNRS", "CCSTMTTRNRS", "INVSTMTTRNRS":
+					status = &current().Status
+				default:
+					status = nil
+				}
+
+			case "CODE", "SEVERITY", "MESSAGE":
+				if status != nil && parent == "STATUS" {
+					next = statusKeys[t.Name.Local]
+				}
+
+			case "DTSERVER":
+				if parent == "SONRS" {
+					next = dtServer
+				}
+
+			case "DTPROFUP", "DTACCTUP":
+				if parent == "SONRS" {
+					if t.Name.Local == "DTPROFUP" {
+						next = dtProfUp
+					} else {
+						next = dtAcctUp
+					}
+				}
+
+			case "DTSTART", "DTEND":
+				// Only the transaction list bounds describe the statement
+				// period; requests use the same names inside <INCTRAN>.
+				if parent == "BANKTRANLIST" || parent == "INVTRANLIST" {
+					if t.Name.Local == "DTSTART" {
+						next = tranListStart
+					} else {
+						next = tranListEnd
+					}
+				}
+
+			case "LANGUAGE":
+				if parent == "SONRS" {
+					next = language
+				}
+
+			case "ORG", "FID":
+				if parent == "FI" {
+					if t.Name.Local == "ORG" {
+						next = fiOrg
+					} else {
+						next = fiFid
+					}
+				}
+
+			case "CCACCTFROM":
+				current().AccountType = AccountTypeCreditCard
+
+			case "INVACCTFROM":
+				current().AccountType = AccountTypeInvestment
+
+			case "BROKERID":
+				if parent == "INVACCTFROM" {
+					next = brokerID
+				}
+
+			case "BANKACCTTO", "CCACCTTO":
+				if inTrans {
+					trans.DestinationAccount = &Account{}
+					if t.Name.Local == "CCACCTTO" {
+						trans.DestinationAccount.AccountType = AccountTypeCreditCard
+					}
+				}
+
+			case "ACCTID":
+				// <BANKACCTTO> and <CCACCTTO> name the other side of a
+				// transfer, not the statement account.
+				switch {
+				case parent == "BANKACCTFROM" || parent == "CCACCTFROM" || parent == "INVACCTFROM":
+					next = acctID
+				case trans != nil && trans.DestinationAccount != nil && (parent == "BANKACCTTO" || parent == "CCACCTTO"):
+					next = destAcctID
+				}
+
+			case "BRANCHID":
+				switch {
+				case parent == "BANKACCTFROM":
+					next = branchID
+				case trans != nil && trans.DestinationAccount != nil && parent == "BANKACCTTO":
+					next = destBranchID
+				}
+
+			case "BANKID":
+				switch {
+				case parent == "BANKACCTFROM":
+					next = bankID
+				case trans != nil && trans.DestinationAccount != nil && parent == "BANKACCTTO":
+					next = destBankID
+				}
+
+			case "ACCTTYPE":
+				switch {
+				case parent == "BANKACCTFROM":
+					next = acctType
+				case trans != nil && trans.DestinationAccount != nil && parent == "BANKACCTTO":
+					next = destAcctType
+				}
+
+			case "CURDEF":
+				if parent == "STMTRS" || parent == "CCSTMTRS" || parent == "INVSTMTRS" {
+					next = curDef
+				}
+
+			case "BANKTRANLIST":
+				tranList = tranLists[current()]
+				tranLists[current()]++
+				listTags, listRead = 0, 0
+
+			case "STMTTRN":
+				trans = &OfxTransaction{TransactionList: tranList}
+				if listTags >= 0 {
+					listTags++
+				}
+
+			case "STMTTRNP":
+				// Pending transactions, in the <BANKTRANLISTP> of OFX
+				// 2.1 and later, are kept apart from posted ones.
+				if parent == "BANKTRANLISTP" {
+					trans = &OfxTransaction{}
+				}
+
+			case "DTPOSTED", "DTTRAN", "DTUSER", "TRNAMT", "NAME", "TRNTYPE", "CHECKNUM", "SIC":
+				switch {
+				case inTrans:
+					next = transactionKeys[t.Name.Local]
+				case trans != nil && trans.Payee != nil && parent == "PAYEE":
+					next = payeeKeys[t.Name.Local]
+				case bal != nil && parent == "BAL":
+					next = balanceKeys["BAL/"+t.Name.Local]
+				}
+
+			case "PAYEE":
+				if inTrans {
+					trans.Payee = &Payee{}
+				}
+
+			case "ADDR1", "ADDR2", "ADDR3", "CITY", "STATE", "POSTALCODE", "COUNTRY", "PHONE":
+				if trans != nil && trans.Payee != nil && parent == "PAYEE" {
+					next = payeeKeys[t.Name.Local]
+				}
+
+			case "FITID", "MEMO":
+				switch {
+				case invTrans != nil && parent == "INVTRAN":
+					next = investmentKeys[t.Name.Local]
+				case inTrans:
+					next = transactionKeys[t.Name.Local]
+				}
+
+			case "BUYSTOCK", "SELLSTOCK", "BUYMF", "SELLMF", "REINVEST", "INCOME":
+				if parent == "INVTRANLIST" {
+					invTrans = &InvestmentTransaction{Kind: t.Name.Local}
+				}
+
+			case "DTTRADE", "DTSETTLE", "UNIQUEID", "UNIQUEIDTYPE", "UNITS", "UNITPRICE", "COMMISSION", "TOTAL":
+				switch {
+				case invTrans != nil:
+					next = investmentKeys[t.Name.Local]
+				case sec != nil && (parent == "SECINFO" || stackPos > 2 && stack[stackPos-3] == "SECINFO"):
+					// An <OPTINFO> also names its underlying security
+					// in a <SECID> outside of the <SECINFO>.
+					next = securityKeys[parent+"/"+t.Name.Local]
+				}
+
+			case "STOCKINFO", "MFINFO", "DEBTINFO", "OPTINFO", "OTHERINFO":
+				if parent == "SECLIST" {
+					sec = &Security{Kind: t.Name.Local}
+				}
+
+			case "SECNAME", "TICKER":
+				if sec != nil && parent == "SECINFO" {
+					next = securityKeys[parent+"/"+t.Name.Local]
+				}
+
+			case "CURSYM", "CURRATE":
+				if trans != nil && (parent == "CURRENCY" || parent == "ORIGCURRENCY") {
+					trans.OriginalCurrency = parent == "ORIGCURRENCY"
+					if t.Name.Local == "CURSYM" {
+						next = transCurSym
+					} else {
+						next = transCurRate
+					}
+				}
+
+			case "BAL":
+				if parent == "BALLIST" {
+					bal = &NamedBalance{}
+				}
+
+			case "BALAMT", "DTASOF", "DESC", "BALTYPE", "VALUE":
+				// Each balance aggregate carries its own <BALAMT> or
+				// <VALUE> and <DTASOF>.
+				if parent != "BAL" || bal != nil {
+					next = balanceKeys[parent+"/"+t.Name.Local]
+				}
+			}
+
+		case xml.CharData:
+			// Most character data is the whitespace between tags, which
+			// trims to nothing without allocating.
+			res := string(bytes.TrimSpace(t))
+
+			// Vendor extension elements such as <INTU.BID> are named with
+			// a prefix and a period. They and notification elements go to
+			// Extensions without a warning.
+			if next == none && res != "" && stackPos > 1 && !strings.Contains(stack[stackPos-1], ".") &&
+				(stack[stackPos-2] == "STMTTRN" || stack[stackPos-2] == "STMTTRNP") && !ignoredTransactionElements[stack[stackPos-1]] &&
+				!notificationElements[stack[stackPos-1]] {
+				name := stack[stackPos-1]
+				if opts.unknownElements != nil {
+					opts.unknownElements[name]++
+				}
+				if err := warn(fmt.Sprintf("Ignored unknown transaction element <%s>", name), fmt.Sprintf("Unknown transaction element <%s>", name)); err != nil {
+					return nil, err
+				}
+			}
+			if next == none && res != "" && stackPos > 0 && (strings.Contains(stack[stackPos-1], ".") || notificationElements[stack[stackPos-1]]) {
+				target := signon
+				if ofx != nil {
+					target = ofx
+				}
+				switch name := stack[stackPos-1]; name {
+				case "INTU.BID":
+					target.AggregatorBankID = res
+				case "INTU.USERID":
+					target.AggregatorUserID = res
+				case "INTU.ACCTID":
+					target.AggregatorAccountID = res
+				case "INTU.ACCTNICK":
+					target.AccountNickname = res
+				default:
+					if target.Extensions == nil {
+						target.Extensions = map[string]string{}
+					}
+					target.Extensions[name] = res
+				}
+			}
+
+			// An empty element such as <MEMO></MEMO> leaves its field
+			// unset, rather than failing to parse as a date or amount.
+			if res == "" {
+				next = none
+				break
+			}
+
+			switch next {
+			case acctID:
+				current().AccountNumber = res
+
+			// case branchID:
+			//	current().BranchCode = res
+
+			case bankID:
+				current().AccountBankNumber = res
+
+			case transDesc:
+				trans.Name = res
+
+			case transMemo:
+				trans.Memo = res
+
+			case transFitID:
+				trans.FitID = res
+
+			case curDef:
+				current().Currency = res
+
+			case acctType:
+				current().AccountType = res
+
+			case transDatePosted:
+				if t, err := parseDateTimeIn(res, naive); err != nil {
+					return nil, err
+				} else {
+					trans.PostedDateTime = t
+				}
+
+			case transUserDate:
+				if t, err := parseDateTimeIn(res, naive); err != nil {
+					return nil, err
+				} else {
+					trans.UserDateTime = t
+				}
+
+			case transAmount:
+				if opts.rawAmts {
+					trans.RawAmount = res
+				}
+				if d, err := parseAmount(res, CurrencyPlaces(current().Currency), opts.excess); err != nil {
+					transErr = fmt.Errorf("TRNAMT: %w", err)
+				} else {
+					trans.Amount = d
+				}
+
+			case transType:
+				trans.Type = res
+
+			case transCheckNum:
+				trans.CheckNum = res
+
+			case transSIC:
+				trans.SIC = res
+
+			case destBankID:
+				trans.DestinationAccount.BankID = res
+
+			case destBranchID:
+				trans.DestinationAccount.BranchID = res
+
+			case destAcctID:
+				trans.DestinationAccount.AccountID = res
+
+			case destAcctType:
+				trans.DestinationAccount.AccountType = res
+
+			case payeeName:
+				trans.Payee.Name = res
+
+			case payeeAddr1:
+				trans.Payee.Addr1 = res
+
+			case payeeAddr2:
+				trans.Payee.Addr2 = res
+
+			case payeeAddr3:
+				trans.Payee.Addr3 = res
+
+			case payeeCity:
+				trans.Payee.City = res
+
+			case payeeState:
+				trans.Payee.State = res
+
+			case payeePostalCode:
+				trans.Payee.PostalCode = res
+
+			case payeeCountry:
+				trans.Payee.Country = res
+
+			case payeePhone:
+				trans.Payee.Phone = res
+
+			case transCurSym:
+				trans.Currency = res
+
+			case transCurRate:
+				if f, err := parseNumber(res); err != nil {
+					transErr = fmt.Errorf("CURRATE: Invalid number: '%s'", res)
+				} else {
+					trans.CurrencyRate = f
+				}
+
+			case dtServer:
+				if t, err := parseDateTimeIn(res, naive); err != nil {
+					return nil, err
+				} else {
+					signon.GeneratedDateTime = t
+					if opts.serverZone && strings.HasSuffix(res, "]") {
+						naive = t.Location()
+					}
+				}
+
+			case tranListStart:
+				// A statement with several transaction lists covers
+				// the period of all of them.
+				if t, err := parseDateTimeIn(res, naive); err != nil {
+					return nil, err
+				} else if start := current().TransactionStartDateTime; start.IsZero() || t.Before(start) {
+					current().TransactionStartDateTime = t
+				}
+
+			case tranListEnd:
+				if t, err := parseDateTimeIn(res, naive); err != nil {
+					return nil, err
+				} else if t.After(current().TransactionEndDateTime) {
+					current().TransactionEndDateTime = t
+				}
+
+			case dtProfUp:
+				if t, err := parseDateTimeIn(res, naive); err != nil {
+					return nil, err
+				} else {
+					signon.ProfileUpdatedDateTime = t
+				}
+
+			case dtAcctUp:
+				if t, err := parseDateTimeIn(res, naive); err != nil {
+					return nil, err
+				} else {
+					signon.AccountUpdatedDateTime = t
+				}
+
+			case language:
+				signon.Language = res
+
+			case statusCode:
+				if code, err := strconv.Atoi(res); err != nil {
+					return nil, fmt.Errorf("Invalid status code: '%s'", res)
+				} else {
+					status.Code = code
+				}
+
+			case statusSeverity:
+				status.Severity = res
+
+			case statusMessage:
+				status.Message = res
+
+			case fiOrg:
+				signon.Institution.Org = res
+
+			case fiFid:
+				signon.Institution.Fid = res
+
+			case brokerID:
+				current().BrokerID = res
+
+			case invFitID:
+				invTrans.FitID = res
+
+			case invMemo:
+				invTrans.Memo = res
+
+			case invDateTrade:
+				if t, err := parseDateTimeIn(res, naive); err != nil {
+					return nil, err
+				} else {
+					invTrans.TradeDateTime = t
+				}
+
+			case invDateSettle:
+				if t, err := parseDateTimeIn(res, naive); err != nil {
+					return nil, err
+				} else {
+					invTrans.SettleDateTime = t
+				}
+
+			case invSecID:
+				invTrans.SecurityID = res
+
+			case invSecIDType:
+				invTrans.SecurityIDType = res
+
+			case invUnits:
+				if f, err := parseNumber(res); err != nil {
+					transErr = fmt.Errorf("UNITS: Invalid number: '%s'", res)
+				} else {
+					invTrans.Units = f
+				}
+
+			case invUnitPrice:
+				if f, err := parseNumber(res); err != nil {
+					transErr = fmt.Errorf("UNITPRICE: Invalid number: '%s'", res)
+				} else {
+					invTrans.UnitPrice = f
+				}
+
+			case invCommission:
+				if d, err := parseAmount(res, CurrencyPlaces(current().Currency), opts.excess); err != nil {
+					transErr = fmt.Errorf("COMMISSION: %w", err)
+				} else {
+					invTrans.Commission = d
+				}
+
+			case invTotal:
+				if d, err := parseAmount(res, CurrencyPlaces(current().Currency), opts.excess); err != nil {
+					transErr = fmt.Errorf("TOTAL: %w", err)
+				} else {
+					invTrans.Total = d
+				}
+
+			case secUniqueID:
+				sec.UniqueID = res
+
+			case secUniqueIDType:
+				sec.UniqueIDType = res
+
+			case secName:
+				sec.Name = res
+
+			case secTicker:
+				sec.Ticker = res
+
+			case secUnitPrice:
+				if f, err := parseNumber(res); err != nil {
+					return nil, fmt.Errorf("Failed to parse security '%s' UNITPRICE: Invalid number: '%s'", sec.UniqueID, res)
+				} else {
+					sec.UnitPrice = f
+				}
+
+			case legerBal:
+				if d, err := parseAmount(res, CurrencyPlaces(current().Currency), opts.excess); err != nil {
+					return nil, fmt.Errorf("Failed to parse LEDGERBAL: %w", err)
+				} else {
+					current().LedgerBalance = d
+				}
+			case AvailBal:
+				if d, err := parseAmount(res, CurrencyPlaces(current().Currency), opts.excess); err != nil {
+					return nil, fmt.Errorf("Failed to parse AVAILBAL: %w", err)
+				} else {
+					current().AvailableBalance = d
+				}
+
+			case balName:
+				bal.Name = res
+
+			case balDesc:
+				bal.Description = res
+
+			case balType:
+				bal.Type = res
+
+			case balValue:
+				if d, err := parseDecimalExact(res); err != nil {
+					return nil, fmt.Errorf("Failed to parse BAL '%s': %w", bal.Name, err)
+				} else {
+					bal.Value = d
+				}
+
+			case balDate:
+				if t, err := parseDateTimeIn(res, naive); err != nil {
+					return nil, err
+				} else {
+					bal.AsOfDateTime = t
+				}
+
+			case legerBalDate:
+				if t, err := parseDateTimeIn(res, naive); err != nil {
+					return nil, err
+				} else {
+					current().LedgerBalanceDate = t
+				}
+
+			case availBalDate:
+				if t, err := parseDateTimeIn(res, naive); err != nil {
+					return nil, err
+				} else {
+					current().AvailableBalanceDate = t
+				}
+			}
+
+			next = none
+
+		case xml.EndElement:
+			// A value must directly follow its start tag, so an element
+			// closed without one, such as <MEMO/>, leaves nothing pending
+			// for the text after it.
+			next = none
+
+			// An end tag that matches no open element is stray, and is
+			// ignored rather than closing everything up to the root.
+			open := stackPos - 1
+			for open >= 0 && stack[open] != t.Name.Local {
+				open--
+			}
+			if open < 0 {
+				if err := warn(fmt.Sprintf("Ignored unmatched end tag </%s>", t.Name.Local), fmt.Sprintf("Unmatched end tag </%s>", t.Name.Local)); err != nil {
+					return nil, err
+				}
+				break
+			}
+
+			// Pop the element along with any left open inside it, closing
+			// each exactly once, innermost first.
+			for stackPos > open {
+				name := stack[stackPos-1]
+
+				if skipFrom != 0 {
+					if stackPos == skipFrom {
+						skipFrom = 0
+					}
+					stackPos--
+					continue
+				}
+
+				if trans != nil && (name == "STMTTRN" || name == "STMTTRNP") {
+					if name != t.Name.Local {
+						if err := warn(fmt.Sprintf("Closed <%s> without its end tag", name), fmt.Sprintf("Missing </%s> for FITID '%s'", name, trans.FitID)); err != nil {
+							return nil, err
+						}
+					}
+					if err := addTransaction(name == "STMTTRNP"); err != nil {
+						return nil, err
+					}
+				}
+
+				if bal != nil && name == "BAL" {
+					current().Balances = append(current().Balances, *bal)
+					bal = nil
+				}
+
+				if invTrans != nil && name == invTrans.Kind {
+					if transErr != nil {
+						return nil, fmt.Errorf("Failed to parse investment transaction FITID '%s': %w", invTrans.FitID, transErr)
+					}
+					current().InvestmentTransactions = append(current().InvestmentTransactions, invTrans)
+					invTrans = nil
+				}
+
+				// Bank accounts always carry an <ACCTTYPE>, unlike credit
+				// card and investment ones whose type is the block itself.
+				if name == "BANKACCTFROM" && trans == nil && ofx != nil && ofx.AccountType == "" {
+					if err := warn("<BANKACCTFROM> has no <ACCTTYPE>", "Missing <ACCTTYPE> in <BANKACCTFROM>"); err != nil {
+						return nil, err
+					}
+				}
+
+				if sec != nil && name == sec.Kind {
+					securities[sec.UniqueID] = *sec
+					sec = nil
+				}
+
+				if name == "BANKTRANLIST" {
+					if err := checkList(); err != nil {
+						return nil, err
+					}
+				}
+
+				if name == "STMTTRNRS" || name == "CCSTMTTRNRS" || name == "INVSTMTTRNRS" {
+					if ofx != nil && ofx.AccountNumber != "" {
+						for i, s := range doc.Statements {
+							if s != ofx && s.account() == ofx.account() {
+								if err := warn(fmt.Sprintf("Repeated statement for the account of statement %d", i+1),
+									fmt.Sprintf("Duplicate statement for account %s", ofx.AccountNumber)); err != nil {
+									return nil, err
+								}
+								break
+							}
+						}
+					}
+					ofx = nil
+				}
+
+				stackPos--
+			}
+
+		case xml.ProcInst:
+			// readHeader normally consumes the <?xml?> and <?OFX?>
+			// instructions, but stops at anything unexpected before them.
+			if t.Target == "OFX" && stackPos == 0 && doc.Header.OFXHeader == "" {
+				doc.Header.setProcInst(string(t.Inst))
+				if err := checkVersion(doc.Header); err != nil {
+					return nil, err
+				}
+			}
+
+		case xml.Comment, xml.Directive:
+			// Neither carries statement data.
+
+		default:
+			if err := warn(fmt.Sprintf("Ignored unexpected token %T", t), fmt.Sprintf("Unexpected token %T", t)); err != nil {
+				return nil, err
+			}
+		}
+
+		tok, err = dec.Token()
+
+		if err != nil && err != io.EOF {
+			if err := warn(fmt.Sprintf("Stopped reading malformed input: %s", err), fmt.Sprintf("Malformed input: %s", err)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if opts.ctx != nil {
+		if err := opts.ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	// A file truncated within a transaction never closes it, but what was
+	// read of it is kept rather than silently lost.
+	if trans != nil {
+		pending := false
+		for _, name := range stack[:stackPos] {
+			pending = pending || name == "STMTTRNP"
+		}
+		if err := warn("Kept incomplete transaction at end of input",
+			fmt.Sprintf("Incomplete transaction FITID '%s' at end of input", trans.FitID)); err != nil {
+			return nil, err
+		}
+		if err := addTransaction(pending); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkList(); err != nil {
+		return nil, err
+	}
+
+	if !seenRoot {
+		return nil, fmt.Errorf("%w: no <OFX> element", ErrNotOFX)
+	}
+
+	if len(doc.Statements) == 0 && otherMessageSet != "" {
+		return nil, fmt.Errorf("%w: <%s>", ErrNotStatement, otherMessageSet)
+	}
+	if len(doc.Statements) == 0 {
+		current()
+	}
+	for _, s := range doc.Statements {
+		// A reversed period is swapped so that it still covers the
+		// transactions, warning on s.
+		ofx = s
+		if s.reversedPeriod() {
+			start, end := formatDateTime(s.TransactionStartDateTime), formatDateTime(s.TransactionEndDateTime)
+			if err := warn(fmt.Sprintf("Swapped DTSTART %s and DTEND %s, which were reversed", start, end),
+				fmt.Sprintf("DTSTART %s is later than DTEND %s", start, end)); err != nil {
+				return nil, err
+			}
+			s.TransactionStartDateTime, s.TransactionEndDateTime = s.TransactionEndDateTime, s.TransactionStartDateTime
+		}
+		s.setSignon(signon)
+		if s.AccountType == AccountTypeInvestment && len(securities) > 0 {
+			s.Securities = securities
+			s.resolveSecurities()
+		}
+	}
+
+	if signon.SignonStatus.Severity == SeverityError {
+		return nil, &StatusError{Status: signon.SignonStatus}
+	}
+	for _, s := range doc.Statements {
+		if s.Status.Severity == SeverityError {
+			return nil, &StatusError{Status: s.Status}
+		}
+	}
+
+	return doc, nil
+
+}
@@ -0,0 +1,54 @@
+package ofx
+
+import (
+	"io"
+)
+
+// ParseResult is a parsed OFX document together with diagnostics about it.
+type ParseResult struct {
+	Header Header `json:"header"`
+
+	// Version is the OFX version declared by the header, e.g. "103" or
+	// "211", and Dialect is DialectSGML or DialectXML.
+	Version string `json:"version"`
+	Dialect string `json:"dialect"`
+
+	Statements []*Ofx `json:"statements"`
+
+	// Warnings holds every distinct warning of every statement, in order.
+	Warnings []string `json:"warnings"`
+
+	// UnknownElements counts the occurrences of each unknown transaction
+	// element, including those only warned about once.
+	UnknownElements map[string]int `json:"unknown_elements"`
+}
+
+// ParseFull is like ParseDocument, but also returns the warnings of the
+// document and statistics about its contents.
+func ParseFull(f io.Reader, opts ...Option) (*ParseResult, error) {
+	o := newOptions(opts)
+	o.unknownElements = map[string]int{}
+	doc, err := parseDocument(f, nil, o)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ParseResult{
+		Header:          doc.Header,
+		Version:         doc.Header.Version,
+		Dialect:         doc.Header.Dialect(),
+		Statements:      doc.Statements,
+		Warnings:        []string{},
+		UnknownElements: o.unknownElements,
+	}
+	seen := map[string]bool{}
+	for _, s := range doc.Statements {
+		for _, w := range s.Warnings {
+			if !seen[w] {
+				seen[w] = true
+				res.Warnings = append(res.Warnings, w)
+			}
+		}
+	}
+	return res, nil
+}
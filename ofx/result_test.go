@@ -0,0 +1,49 @@
+package ofx
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseFull(t *testing.T, path string) *ParseResult {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	res, err := ParseFull(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestParseFull(t *testing.T) {
+	res := parseFull(t, "testdata/unknown.ofx")
+
+	if res.Version != "103" || res.Dialect != DialectSGML {
+		t.Errorf("Wrong version. Expected: 103 %s Actual: %s %s\n", DialectSGML, res.Version, res.Dialect)
+	}
+	if len(res.Statements) != 1 || len(res.Statements[0].Transactions) != 2 {
+		t.Errorf("Wrong statements. Expected: 1 with 2 transactions Actual: %v\n", res.Statements)
+	}
+
+	warnings := strings.Join(res.Warnings, "\n")
+	if len(res.Warnings) != 2 || !strings.Contains(warnings, "<LOYALTYPOINTS>") || !strings.Contains(warnings, "malformed") {
+		t.Errorf("Wrong warnings. Expected: <LOYALTYPOINTS> and malformed input Actual: %q\n", res.Warnings)
+	}
+	if expected := map[string]int{"LOYALTYPOINTS": 2}; !reflect.DeepEqual(res.UnknownElements, expected) {
+		t.Errorf("Wrong unknown elements. Expected: %v Actual: %v\n", expected, res.UnknownElements)
+	}
+
+	clean := parseFull(t, "testdata/v211.xml")
+	if clean.Version != "211" || clean.Dialect != DialectXML {
+		t.Errorf("Wrong version. Expected: 211 %s Actual: %s %s\n", DialectXML, clean.Version, clean.Dialect)
+	}
+	if len(clean.Warnings) != 0 || len(clean.UnknownElements) != 0 {
+		t.Errorf("Expected no warnings. Actual: %q %v\n", clean.Warnings, clean.UnknownElements)
+	}
+}
@@ -0,0 +1,24 @@
+package ofx
+
+// Security is an entry of the <SECLIST> of an investment download, naming
+// the security an investment transaction refers to by its <SECID>. Kind
+// holds the name of the OFX aggregate, e.g. STOCKINFO or MFINFO, and
+// UnitPrice the price per unit the institution last reported.
+type Security struct {
+	Kind         string  `json:"kind"`
+	UniqueID     string  `json:"unique_id"`
+	UniqueIDType string  `json:"unique_id_type"`
+	Name         string  `json:"name"`
+	Ticker       string  `json:"ticker,omitempty"`
+	UnitPrice    float64 `json:"unit_price,omitempty"`
+}
+
+// resolveSecurities sets the SecurityName and Ticker of each investment
+// transaction whose SecurityID is in o.Securities.
+func (o *Ofx) resolveSecurities() {
+	for _, t := range o.InvestmentTransactions {
+		if s, ok := o.Securities[t.SecurityID]; ok {
+			t.SecurityName, t.Ticker = s.Name, s.Ticker
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package ofx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// sgmlDecoder produces XML tokens from either OFX 2.x XML or OFX 1.x SGML.
+//
+// In SGML, leaf elements carry a value but usually no closing tag, e.g.
+// <TRNAMT>12.34 followed directly by <FITID>. Whenever an element has been
+// given a value and the next tag is not its own end tag, a matching
+// xml.EndElement is synthesized so that callers always see closed leaves.
+// Bare '&' characters, which are common in SGML payee names, are tolerated.
+//
+// OFX element names are uppercase, but some exporters write <trnamt> or
+// <TrnAmt>, so names are uppercased before they are matched or returned.
+type sgmlDecoder struct {
+	dec *xml.Decoder
+
+	// start is the most recently opened element, until anything other
+	// than character data follows it.
+	start string
+
+	// leaf is an element that has received a value but no end tag yet.
+	leaf string
+
+	pending []xml.Token
+}
+
+func newSGMLDecoder(r io.Reader) *sgmlDecoder {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	return &sgmlDecoder{dec: dec}
+}
+
+// Token returns the next token in the input. Like xml.Decoder.RawToken, the
+// returned CharData is only valid until the next call to Token.
+func (d *sgmlDecoder) Token() (xml.Token, error) {
+	if len(d.pending) > 0 {
+		tok := d.pending[0]
+		d.pending = d.pending[1:]
+		return tok, nil
+	}
+
+	tok, err := d.dec.RawToken()
+	if err != nil {
+		if err == io.EOF && d.leaf != "" {
+			return d.closeLeaf(), nil
+		}
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case xml.StartElement:
+		t.Name.Local = strings.ToUpper(t.Name.Local)
+		tok = t
+		d.start = t.Name.Local
+		if d.leaf != "" {
+			d.pending = append(d.pending, t)
+			return d.closeLeaf(), nil
+		}
+
+	case xml.EndElement:
+		t.Name.Local = strings.ToUpper(t.Name.Local)
+		tok = t
+		d.start = ""
+		if d.leaf != "" && d.leaf != t.Name.Local {
+			d.pending = append(d.pending, t)
+			return d.closeLeaf(), nil
+		}
+		d.leaf = ""
+
+	case xml.CharData:
+		if d.start != "" && len(bytes.TrimSpace(t)) > 0 {
+			d.leaf = d.start
+			d.start = ""
+		}
+	}
+
+	return tok, nil
+}
+
+func (d *sgmlDecoder) closeLeaf() xml.Token {
+	end := xml.EndElement{Name: xml.Name{Local: d.leaf}}
+	d.leaf = ""
+	return end
+}
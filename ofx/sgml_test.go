@@ -0,0 +1,92 @@
+package ofx
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSGMLDecoderClosesLeaves(t *testing.T) {
+	dec := newSGMLDecoder(strings.NewReader("<STMTTRN><TRNAMT>-1.00\n<NAME>AT&T</NAME><MEMO>x</STMTTRN>"))
+
+	var actual []string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			actual = append(actual, "<"+tok.Name.Local+">")
+		case xml.EndElement:
+			actual = append(actual, "</"+tok.Name.Local+">")
+		case xml.CharData:
+			actual = append(actual, strings.TrimSpace(string(tok)))
+		}
+	}
+
+	expected := []string{
+		"<STMTTRN>",
+		"<TRNAMT>", "-1.00", "</TRNAMT>",
+		"<NAME>", "AT&T", "</NAME>",
+		"<MEMO>", "x", "</MEMO>",
+		"</STMTTRN>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Wrong tokens. Expected: %v Actual: %v\n", expected, actual)
+	}
+}
+
+func TestParseSGMLMatchesXML(t *testing.T) {
+	sgml := parseFile(t, "testdata/sgml.ofx")
+	plain := parseFile(t, "testdata/sgml.xml")
+
+	if len(sgml.Transactions) != 3 {
+		t.Fatalf("Wrong number of transactions. Expected: 3 Actual: %d\n", len(sgml.Transactions))
+	}
+	if sgml.Transactions[0].Name != "AT&T PAYMENT" {
+		t.Errorf("Wrong name. Expected: %s Actual: %s\n", "AT&T PAYMENT", sgml.Transactions[0].Name)
+	}
+
+	// The 1.x and 2.x headers legitimately differ; only the bodies should match.
+	sgml.Header, plain.Header = Header{}, Header{}
+
+	expected, err := json.Marshal(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := json.Marshal(sgml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != string(expected) {
+		t.Errorf("SGML and XML statements differ.\nExpected: %s\nActual:   %s\n", expected, actual)
+	}
+}
+
+func TestParseMixedCaseTags(t *testing.T) {
+	expected := parseFile(t, "testdata/v103.ofx")
+	actual := parseFile(t, "testdata/mixedcase.ofx")
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Wrong mixed-case statement.\nExpected: %s\nActual:   %s\n", expected, actual)
+	}
+
+	// XML end tags may differ in case from their start tags too.
+	_ofx, err := Parse(strings.NewReader(`<ofx><BankTranList><stmtTrn><TrnAmt>-1.50</TRNAMT><fitid>X1</FitId></STMTTRN></banktranlist></OFX>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(_ofx.Transactions) != 1 || _ofx.Transactions[0].FitID != "X1" || _ofx.Transactions[0].Amount.String() != "-1.50" {
+		t.Errorf("Wrong mixed-case XML transactions. Actual: %v\n", _ofx.Transactions)
+	}
+	if _ofx.Warnings != nil {
+		t.Errorf("Expected no warnings. Actual: %q\n", _ofx.Warnings)
+	}
+}
@@ -0,0 +1,24 @@
+package ofx
+
+import "sort"
+
+// SortByDate sorts the transactions by PostedDateTime, oldest first when
+// ascending is set and newest first otherwise. Transactions posted at the
+// same instant are ordered by FITID, in the same direction, so the result
+// does not depend on the order of the file.
+func (o *Ofx) SortByDate(ascending bool) {
+	sort.SliceStable(o.Transactions, func(i, j int) bool {
+		if ascending {
+			return postedBefore(o.Transactions[i], o.Transactions[j])
+		}
+		return postedBefore(o.Transactions[j], o.Transactions[i])
+	})
+}
+
+// postedBefore orders transactions by PostedDateTime, then FITID.
+func postedBefore(a, b *OfxTransaction) bool {
+	if !a.PostedDateTime.Equal(b.PostedDateTime) {
+		return a.PostedDateTime.Before(b.PostedDateTime)
+	}
+	return a.FitID < b.FitID
+}
@@ -0,0 +1,29 @@
+package ofx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortByDate(t *testing.T) {
+	tests := []struct {
+		ascending bool
+		expected  []string
+	}{
+		{true, []string{"S0", "S1", "S3", "S4", "S5"}},
+		{false, []string{"S5", "S4", "S3", "S1", "S0"}},
+	}
+
+	for _, test := range tests {
+		_ofx := parseFile(t, "testdata/shuffled.ofx")
+		_ofx.SortByDate(test.ascending)
+
+		var fitIDs []string
+		for _, trans := range _ofx.Transactions {
+			fitIDs = append(fitIDs, trans.FitID)
+		}
+		if !reflect.DeepEqual(fitIDs, test.expected) {
+			t.Errorf("Wrong order for ascending=%v. Expected: %v Actual: %v\n", test.ascending, test.expected, fitIDs)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package ofx
+
+// SplitByMonth groups the transactions by the calendar month they were
+// posted in, keyed by YYYY-MM. Months are taken in the time zone of each
+// PostedDateTime, so a transaction posted at 22:00 EST on January 31 is in
+// 2023-01 although it is February in UTC. Each month keeps the transactions
+// in the order of the statement, and months without any are left out.
+func (o *Ofx) SplitByMonth() map[string][]*OfxTransaction {
+	months := map[string][]*OfxTransaction{}
+	for _, t := range o.Transactions {
+		month := t.PostedDateTime.Format("2006-01")
+		months[month] = append(months[month], t)
+	}
+	return months
+}
@@ -0,0 +1,30 @@
+package ofx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitByMonth(t *testing.T) {
+	months := parseFile(t, "testdata/quarter.ofx").SplitByMonth()
+
+	// Q2 is posted late on January 31 EST, which is February in UTC.
+	expected := map[string][]string{
+		"2023-01": {"Q1", "Q2"},
+		"2023-02": {"Q3", "Q4"},
+		"2023-03": {"Q5"},
+	}
+	actual := map[string][]string{}
+	for month, transactions := range months {
+		for _, trans := range transactions {
+			actual[month] = append(actual[month], trans.FitID)
+		}
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Wrong months. Expected: %v Actual: %v\n", expected, actual)
+	}
+
+	if empty := (&Ofx{}).SplitByMonth(); len(empty) != 0 {
+		t.Errorf("Expected no months. Actual: %v\n", empty)
+	}
+}
@@ -0,0 +1,31 @@
+package ofx
+
+import (
+	"fmt"
+)
+
+// SeverityError is the Status severity of a request the server could not
+// fulfil. The other severities are INFO and WARN.
+const SeverityError = "ERROR"
+
+// Status is the <STATUS> aggregate of a response, telling whether the server
+// fulfilled the request. Code 0 means success.
+type Status struct {
+	Code     int    `json:"code"`
+	Severity string `json:"severity"`
+	Message  string `json:"message,omitempty"`
+}
+
+// StatusError is returned by the parse functions when the signon or a
+// statement response has severity ERROR, so that a failed download is not
+// mistaken for an empty statement.
+type StatusError struct {
+	Status Status
+}
+
+func (e *StatusError) Error() string {
+	if e.Status.Message == "" {
+		return fmt.Sprintf("OFX server error %d", e.Status.Code)
+	}
+	return fmt.Sprintf("OFX server error %d: %s", e.Status.Code, e.Status.Message)
+}
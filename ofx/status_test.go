@@ -0,0 +1,41 @@
+package ofx
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	_ofx := parseFile(t, "testdata/v103.ofx")
+
+	expected := Status{Code: 0, Severity: "INFO"}
+	if _ofx.SignonStatus != expected {
+		t.Errorf("Wrong signon status. Expected: %+v Actual: %+v\n", expected, _ofx.SignonStatus)
+	}
+	if _ofx.Status != expected {
+		t.Errorf("Wrong statement status. Expected: %+v Actual: %+v\n", expected, _ofx.Status)
+	}
+}
+
+func TestParseStatusError(t *testing.T) {
+	f, err := os.Open("testdata/statuserror.ofx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_, err = Parse(f)
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Expected a *StatusError. Actual: %v\n", err)
+	}
+	expected := Status{Code: 2003, Severity: SeverityError, Message: "Account not found"}
+	if statusErr.Status != expected {
+		t.Errorf("Wrong status. Expected: %+v Actual: %+v\n", expected, statusErr.Status)
+	}
+	if msg := "OFX server error 2003: Account not found"; err.Error() != msg {
+		t.Errorf("Wrong error. Expected: %s Actual: %s\n", msg, err)
+	}
+}
@@ -0,0 +1,103 @@
+package ofx
+
+import (
+	"strings"
+)
+
+// TransactionType is a normalized <TRNTYPE> code of a transaction. The
+// constants are the codes defined by the OFX specification; every other
+// value maps to TransactionOther.
+type TransactionType string
+
+const (
+	TransactionCredit      TransactionType = "CREDIT"
+	TransactionDebit       TransactionType = "DEBIT"
+	TransactionInterest    TransactionType = "INT"
+	TransactionDividend    TransactionType = "DIV"
+	TransactionFee         TransactionType = "FEE"
+	TransactionServiceChg  TransactionType = "SRVCHG"
+	TransactionDeposit     TransactionType = "DEP"
+	TransactionATM         TransactionType = "ATM"
+	TransactionPOS         TransactionType = "POS"
+	TransactionTransfer    TransactionType = "XFER"
+	TransactionCheck       TransactionType = "CHECK"
+	TransactionPayment     TransactionType = "PAYMENT"
+	TransactionCash        TransactionType = "CASH"
+	TransactionDirectDep   TransactionType = "DIRECTDEP"
+	TransactionDirectDebit TransactionType = "DIRECTDEBIT"
+	TransactionRepeatPmt   TransactionType = "REPEATPMT"
+	TransactionHold        TransactionType = "HOLD"
+	TransactionOther       TransactionType = "OTHER"
+)
+
+var transactionTypes = map[TransactionType]bool{
+	TransactionCredit: true, TransactionDebit: true, TransactionInterest: true,
+	TransactionDividend: true, TransactionFee: true, TransactionServiceChg: true,
+	TransactionDeposit: true, TransactionATM: true, TransactionPOS: true,
+	TransactionTransfer: true, TransactionCheck: true, TransactionPayment: true,
+	TransactionCash: true, TransactionDirectDep: true, TransactionDirectDebit: true,
+	TransactionRepeatPmt: true, TransactionHold: true, TransactionOther: true,
+}
+
+// Valid reports whether t is one of the codes defined by the OFX
+// specification.
+func (t TransactionType) Valid() bool {
+	return transactionTypes[t]
+}
+
+// ParseTransactionType normalizes a raw <TRNTYPE> value, ignoring case and
+// surrounding space. Unknown values yield TransactionOther and false.
+func ParseTransactionType(s string) (TransactionType, bool) {
+	t := TransactionType(strings.ToUpper(strings.TrimSpace(s)))
+	if !t.Valid() {
+		return TransactionOther, false
+	}
+	return t, true
+}
+
+// NormalizedType returns the TransactionType of the raw Type of t.
+func (t OfxTransaction) NormalizedType() TransactionType {
+	tt, _ := ParseTransactionType(t.Type)
+	return tt
+}
+
+// debitTypes and creditTypes are the types whose money always leaves or
+// always enters the account. ATM, POS, XFER, HOLD and OTHER may go either way.
+var (
+	debitTypes = map[TransactionType]bool{
+		TransactionDebit: true, TransactionFee: true, TransactionServiceChg: true,
+		TransactionCheck: true, TransactionPayment: true, TransactionCash: true,
+		TransactionDirectDebit: true, TransactionRepeatPmt: true,
+	}
+	creditTypes = map[TransactionType]bool{
+		TransactionCredit: true, TransactionInterest: true, TransactionDividend: true,
+		TransactionDeposit: true, TransactionDirectDep: true,
+	}
+)
+
+// Sign returns -1 for the types that always take money out of the account,
+// such as DEBIT, FEE or CHECK, 1 for those that always put it in, such as
+// CREDIT, DEP or INT, and 0 for those whose direction only the amount tells.
+func (t TransactionType) Sign() int {
+	switch {
+	case debitTypes[t]:
+		return -1
+	case creditTypes[t]:
+		return 1
+	}
+	return 0
+}
+
+// NormalizeSigns makes the amount of every transaction and pending
+// transaction whose type has a Sign carry that sign, for banks that write
+// every amount as positive. Other amounts are left as they are.
+func (o *Ofx) NormalizeSigns() {
+	for _, list := range [][]*OfxTransaction{o.Transactions, o.PendingTransactions} {
+		for _, t := range list {
+			sign := t.NormalizedType().Sign()
+			if c := t.Amount.cmp(Decimal{}); c != 0 && c != sign && sign != 0 {
+				t.Amount = Decimal{}.Sub(t.Amount)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package ofx
+
+import (
+	"testing"
+)
+
+func TestParseTransactionType(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected TransactionType
+		ok       bool
+	}{
+		{"CREDIT", TransactionCredit, true},
+		{"DEBIT", TransactionDebit, true},
+		{"INT", TransactionInterest, true},
+		{"DIV", TransactionDividend, true},
+		{"FEE", TransactionFee, true},
+		{"SRVCHG", TransactionServiceChg, true},
+		{"DEP", TransactionDeposit, true},
+		{"ATM", TransactionATM, true},
+		{"POS", TransactionPOS, true},
+		{"XFER", TransactionTransfer, true},
+		{"CHECK", TransactionCheck, true},
+		{"PAYMENT", TransactionPayment, true},
+		{"CASH", TransactionCash, true},
+		{"DIRECTDEP", TransactionDirectDep, true},
+		{"DIRECTDEBIT", TransactionDirectDebit, true},
+		{"REPEATPMT", TransactionRepeatPmt, true},
+		{"HOLD", TransactionHold, true},
+		{"OTHER", TransactionOther, true},
+		{" pos ", TransactionPOS, true},
+		{"REFUND", TransactionOther, false},
+		{"", TransactionOther, false},
+	}
+	for _, test := range tests {
+		actual, ok := ParseTransactionType(test.raw)
+		if actual != test.expected || ok != test.ok {
+			t.Errorf("Wrong type for '%s'. Expected: %s/%t Actual: %s/%t\n", test.raw, test.expected, test.ok, actual, ok)
+		}
+	}
+}
+
+func TestNormalizedType(t *testing.T) {
+	_ofx := parseFile(t, "testdata/checknum.ofx")
+
+	check := _ofx.Transactions[0]
+	if check.Type != "CHECK" || check.NormalizedType() != TransactionCheck {
+		t.Errorf("Wrong check type. Expected: CHECK/CHECK Actual: %s/%s\n", check.Type, check.NormalizedType())
+	}
+
+	unknown := OfxTransaction{Type: "REFUND"}
+	if unknown.NormalizedType() != TransactionOther || unknown.Type != "REFUND" {
+		t.Errorf("Wrong unknown type. Expected: REFUND/OTHER Actual: %s/%s\n", unknown.Type, unknown.NormalizedType())
+	}
+}
+
+func TestNormalizeSigns(t *testing.T) {
+	_ofx := parseFile(t, "testdata/positive.ofx")
+	_ofx.NormalizeSigns()
+
+	// ATM goes either way, so its amount is kept.
+	expected := []string{"-12.00", "-2.50", "-250.00", "500.00", "40.00", "60.00"}
+	for i, trans := range _ofx.Transactions {
+		if actual := trans.Amount.String(); actual != expected[i] {
+			t.Errorf("Wrong amount for %s %s. Expected: %s Actual: %s\n", trans.Type, trans.FitID, expected[i], actual)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package ofx
+
+import (
+	"fmt"
+)
+
+// ValidationError is an integrity problem reported by Validate. FitID
+// identifies the offending transaction, or is empty for problems with the
+// statement as a whole.
+type ValidationError struct {
+	FitID   string
+	Problem string
+}
+
+func (e *ValidationError) Error() string {
+	if e.FitID == "" {
+		return e.Problem
+	}
+	return fmt.Sprintf("Transaction FITID '%s': %s", e.FitID, e.Problem)
+}
+
+// Validate checks the statement for common signs of a corrupt export: a
+// missing account id, duplicate FITIDs and transactions posted outside the
+// DTSTART/DTEND period. Each problem is returned as a *ValidationError; the
+// result is empty when none were found.
+func (o *Ofx) Validate() []error {
+	var errs []error
+
+	if o.AccountNumber == "" {
+		errs = append(errs, &ValidationError{Problem: "Missing account id"})
+	}
+
+	seen := map[string]bool{}
+	for _, t := range o.Transactions {
+		if t.FitID != "" {
+			if seen[t.FitID] {
+				errs = append(errs, &ValidationError{FitID: t.FitID, Problem: "Duplicate FITID"})
+			}
+			seen[t.FitID] = true
+		}
+
+		start, end := o.TransactionStartDateTime, o.TransactionEndDateTime
+		if (!start.IsZero() && t.PostedDateTime.Before(start)) || (!end.IsZero() && t.PostedDateTime.After(end)) {
+			errs = append(errs, &ValidationError{
+				FitID:   t.FitID,
+				Problem: fmt.Sprintf("Posted %s outside the statement period %s to %s", t.PostedDateTime, start, end),
+			})
+		}
+	}
+
+	return errs
+}
+
+// ValidateFrom runs Validate and also checks that start, the balance before
+// the first transaction, plus the sum of all transactions equals the ledger
+// balance.
+func (o *Ofx) ValidateFrom(start Decimal) []error {
+	errs := o.Validate()
+
+	balance := start
+	for _, t := range o.Transactions {
+		balance = balance.Add(t.Amount)
+	}
+	if balance.cmp(o.LedgerBalance) != 0 {
+		errs = append(errs, &ValidationError{
+			Problem: fmt.Sprintf("Ledger balance %s does not reconcile with %s plus transactions, which gives %s", o.LedgerBalance, start, balance),
+		})
+	}
+
+	return errs
+}
@@ -0,0 +1,61 @@
+package ofx
+
+import (
+	"reflect"
+	"testing"
+)
+
+// problemFitIDs returns the FITID of every ValidationError in errs.
+func problemFitIDs(t *testing.T, errs []error) []string {
+	var ids []string
+	for _, err := range errs {
+		verr, ok := err.(*ValidationError)
+		if !ok {
+			t.Fatalf("Expected a *ValidationError. Actual: %T %v\n", err, err)
+		}
+		ids = append(ids, verr.FitID)
+	}
+	return ids
+}
+
+func TestValidateDuplicateFitIDs(t *testing.T) {
+	errs := parseFile(t, "testdata/duplicates.ofx").Validate()
+
+	if actual, expected := problemFitIDs(t, errs), []string{"D2", "D1"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Wrong duplicates. Expected: %v Actual: %v (%v)\n", expected, actual, errs)
+	}
+}
+
+func TestValidateOutOfRange(t *testing.T) {
+	errs := parseFile(t, "testdata/outofrange.ofx").Validate()
+
+	if actual, expected := problemFitIDs(t, errs), []string{"R1", "R3"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Wrong out of range transactions. Expected: %v Actual: %v (%v)\n", expected, actual, errs)
+	}
+}
+
+func TestValidateClean(t *testing.T) {
+	if errs := parseFile(t, "testdata/v103.ofx").Validate(); len(errs) != 0 {
+		t.Errorf("Expected no problems. Actual: %v\n", errs)
+	}
+}
+
+func TestValidateMissingAccount(t *testing.T) {
+	errs := (&Ofx{}).Validate()
+	if len(errs) != 1 || errs[0].Error() != "Missing account id" {
+		t.Errorf("Expected a missing account id. Actual: %v\n", errs)
+	}
+}
+
+func TestValidateFrom(t *testing.T) {
+	_ofx := parseFile(t, "testdata/outofrange.ofx")
+
+	if errs := _ofx.ValidateFrom(NewDecial("0.00")); len(errs) != 2 {
+		t.Errorf("Expected the balance to reconcile. Actual: %v\n", errs)
+	}
+
+	errs := _ofx.ValidateFrom(NewDecial("10.00"))
+	if ids := problemFitIDs(t, errs); len(ids) != 3 || ids[2] != "" {
+		t.Errorf("Expected a statement level balance problem. Actual: %v\n", errs)
+	}
+}
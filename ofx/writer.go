@@ -0,0 +1,321 @@
+package ofx
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ofxWriter writes indented OFX 2.x XML elements.
+type ofxWriter struct {
+	w     *bufio.Writer
+	depth int
+}
+
+func (ow *ofxWriter) indent() {
+	ow.w.WriteString(strings.Repeat("  ", ow.depth))
+}
+
+func (ow *ofxWriter) open(name string) {
+	ow.indent()
+	ow.w.WriteString("<" + name + ">\n")
+	ow.depth++
+}
+
+func (ow *ofxWriter) close(name string) {
+	ow.depth--
+	ow.indent()
+	ow.w.WriteString("</" + name + ">\n")
+}
+
+// elem writes a leaf element, skipping empty values.
+func (ow *ofxWriter) elem(name, value string) {
+	if value == "" {
+		return
+	}
+	ow.indent()
+	ow.w.WriteString("<" + name + ">")
+	xml.EscapeText(ow.w, []byte(value))
+	ow.w.WriteString("</" + name + ">\n")
+}
+
+func (ow *ofxWriter) dateTime(name string, t time.Time) {
+	if !t.IsZero() {
+		ow.elem(name, formatDateTime(t))
+	}
+}
+
+func (ow *ofxWriter) decimal(name string, d Decimal) {
+	if d != (Decimal{}) {
+		ow.elem(name, d.String())
+	}
+}
+
+func (ow *ofxWriter) float(name string, f float64) {
+	if f != 0 {
+		ow.elem(name, strconv.FormatFloat(f, 'f', -1, 64))
+	}
+}
+
+// WriteOFX writes o as an OFX 2.x XML document, with header, signon response
+// and a single statement response. Datetimes are written in the canonical
+// YYYYMMDDHHMMSS.XXX[offset:tz] form and amounts with the number of decimal
+// places they were parsed with. Zero-valued fields are omitted, so that
+// parsing the output again yields the same statement.
+func WriteOFX(w io.Writer, o *Ofx) error {
+	ow := &ofxWriter{w: bufio.NewWriter(w)}
+
+	ow.w.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n")
+	ow.w.WriteString(`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n")
+
+	ow.open("OFX")
+
+	ow.open("SIGNONMSGSRSV1")
+	ow.open("SONRS")
+	ow.writeStatus(o.SignonStatus)
+	ow.dateTime("DTSERVER", o.GeneratedDateTime)
+	ow.elem("LANGUAGE", o.Language)
+	ow.dateTime("DTPROFUP", o.ProfileUpdatedDateTime)
+	ow.dateTime("DTACCTUP", o.AccountUpdatedDateTime)
+	if o.Institution != (Institution{}) {
+		ow.open("FI")
+		ow.elem("ORG", o.Institution.Org)
+		ow.elem("FID", o.Institution.Fid)
+		ow.close("FI")
+	}
+	ow.close("SONRS")
+	ow.close("SIGNONMSGSRSV1")
+
+	msgs, trnrs, rs, acct, list := "BANKMSGSRSV1", "STMTTRNRS", "STMTRS", "BANKACCTFROM", "BANKTRANLIST"
+	switch o.AccountType {
+	case AccountTypeCreditCard:
+		msgs, trnrs, rs, acct = "CREDITCARDMSGSRSV1", "CCSTMTTRNRS", "CCSTMTRS", "CCACCTFROM"
+	case AccountTypeInvestment:
+		msgs, trnrs, rs, acct, list = "INVSTMTMSGSRSV1", "INVSTMTTRNRS", "INVSTMTRS", "INVACCTFROM", "INVTRANLIST"
+	}
+
+	ow.open(msgs)
+	ow.open(trnrs)
+	ow.elem("TRNUID", "0")
+	ow.writeStatus(o.Status)
+	ow.open(rs)
+	ow.elem("CURDEF", o.Currency)
+
+	ow.open(acct)
+	ow.elem("BROKERID", o.BrokerID)
+	ow.elem("BANKID", o.AccountBankNumber)
+	ow.elem("ACCTID", o.AccountNumber)
+	if acct == "BANKACCTFROM" {
+		ow.elem("ACCTTYPE", o.AccountType)
+	}
+	ow.close(acct)
+
+	ow.open(list)
+	ow.dateTime("DTSTART", o.TransactionStartDateTime)
+	ow.dateTime("DTEND", o.TransactionEndDateTime)
+	for _, t := range o.InvestmentTransactions {
+		ow.writeInvestmentTransaction(t)
+	}
+	for _, t := range o.Transactions {
+		if list == "INVTRANLIST" {
+			ow.open("INVBANKTRAN")
+			ow.writeTransaction(t, false)
+			ow.close("INVBANKTRAN")
+		} else {
+			ow.writeTransaction(t, false)
+		}
+	}
+	ow.close(list)
+
+	ow.writeBalance("LEDGERBAL", o.LedgerBalance, o.LedgerBalanceDate)
+	ow.writeBalance("AVAILBAL", o.AvailableBalance, o.AvailableBalanceDate)
+	if len(o.Balances) > 0 {
+		ow.open("BALLIST")
+		for _, b := range o.Balances {
+			ow.open("BAL")
+			ow.elem("NAME", b.Name)
+			ow.elem("DESC", b.Description)
+			ow.elem("BALTYPE", b.Type)
+			ow.elem("VALUE", b.Value.String())
+			ow.dateTime("DTASOF", b.AsOfDateTime)
+			ow.close("BAL")
+		}
+		ow.close("BALLIST")
+	}
+
+	if len(o.PendingTransactions) > 0 {
+		ow.open("BANKTRANLISTP")
+		for _, t := range o.PendingTransactions {
+			ow.writeTransaction(t, true)
+		}
+		ow.close("BANKTRANLISTP")
+	}
+
+	ow.close(rs)
+	ow.close(trnrs)
+	ow.close(msgs)
+
+	if len(o.Securities) > 0 {
+		ow.writeSecurities(o.Securities)
+	}
+
+	ow.close("OFX")
+
+	return ow.w.Flush()
+}
+
+// writeStatus writes s. An unset status, from input without a <STATUS>, is
+// omitted like other zero-valued fields rather than written as a success.
+func (ow *ofxWriter) writeStatus(s Status) {
+	if s == (Status{}) {
+		return
+	}
+	if s.Severity == "" {
+		s.Severity = "INFO"
+	}
+	ow.open("STATUS")
+	ow.elem("CODE", strconv.Itoa(s.Code))
+	ow.elem("SEVERITY", s.Severity)
+	ow.elem("MESSAGE", s.Message)
+	ow.close("STATUS")
+}
+
+func (ow *ofxWriter) writeBalance(name string, amount Decimal, asOf time.Time) {
+	if amount == (Decimal{}) && asOf.IsZero() {
+		return
+	}
+	ow.open(name)
+	ow.decimal("BALAMT", amount)
+	ow.dateTime("DTASOF", asOf)
+	ow.close(name)
+}
+
+// writeTransaction writes t as a <STMTTRN>, or as a <STMTTRNP> whose
+// PostedDateTime is its <DTTRAN> when pending.
+func (ow *ofxWriter) writeTransaction(t *OfxTransaction, pending bool) {
+	name, posted := "STMTTRN", "DTPOSTED"
+	if pending {
+		name, posted = "STMTTRNP", "DTTRAN"
+	}
+	ow.open(name)
+	ow.elem("TRNTYPE", t.Type)
+	ow.dateTime(posted, t.PostedDateTime)
+	ow.dateTime("DTUSER", t.UserDateTime)
+	ow.decimal("TRNAMT", t.Amount)
+	if !t.SyntheticFitID {
+		ow.elem("FITID", t.FitID)
+	}
+	ow.elem("CHECKNUM", t.CheckNum)
+	ow.elem("SIC", t.SIC)
+	ow.elem("NAME", t.Name)
+	if p := t.Payee; p != nil {
+		ow.open("PAYEE")
+		ow.elem("NAME", p.Name)
+		ow.elem("ADDR1", p.Addr1)
+		ow.elem("ADDR2", p.Addr2)
+		ow.elem("ADDR3", p.Addr3)
+		ow.elem("CITY", p.City)
+		ow.elem("STATE", p.State)
+		ow.elem("POSTALCODE", p.PostalCode)
+		ow.elem("COUNTRY", p.Country)
+		ow.elem("PHONE", p.Phone)
+		ow.close("PAYEE")
+	}
+	if a := t.DestinationAccount; a != nil {
+		if a.AccountType == AccountTypeCreditCard {
+			ow.open("CCACCTTO")
+			ow.elem("ACCTID", a.AccountID)
+			ow.close("CCACCTTO")
+		} else {
+			ow.open("BANKACCTTO")
+			ow.elem("BANKID", a.BankID)
+			ow.elem("BRANCHID", a.BranchID)
+			ow.elem("ACCTID", a.AccountID)
+			ow.elem("ACCTTYPE", a.AccountType)
+			ow.close("BANKACCTTO")
+		}
+	}
+	ow.elem("MEMO", t.Memo)
+	if t.Currency != "" || t.CurrencyRate != 0 {
+		block := "CURRENCY"
+		if t.OriginalCurrency {
+			block = "ORIGCURRENCY"
+		}
+		ow.open(block)
+		ow.float("CURRATE", t.CurrencyRate)
+		ow.elem("CURSYM", t.Currency)
+		ow.close(block)
+	}
+	ow.close(name)
+}
+
+func (ow *ofxWriter) writeInvestmentTransaction(t *InvestmentTransaction) {
+	ow.open(t.Kind)
+
+	// Buys and sells wrap their details in an <INVBUY> or <INVSELL>.
+	wrapper := ""
+	switch {
+	case strings.HasPrefix(t.Kind, "BUY"):
+		wrapper = "INVBUY"
+	case strings.HasPrefix(t.Kind, "SELL"):
+		wrapper = "INVSELL"
+	}
+	if wrapper != "" {
+		ow.open(wrapper)
+	}
+
+	ow.open("INVTRAN")
+	ow.elem("FITID", t.FitID)
+	ow.dateTime("DTTRADE", t.TradeDateTime)
+	ow.dateTime("DTSETTLE", t.SettleDateTime)
+	ow.elem("MEMO", t.Memo)
+	ow.close("INVTRAN")
+
+	ow.open("SECID")
+	ow.elem("UNIQUEID", t.SecurityID)
+	ow.elem("UNIQUEIDTYPE", t.SecurityIDType)
+	ow.close("SECID")
+
+	ow.float("UNITS", t.Units)
+	ow.float("UNITPRICE", t.UnitPrice)
+	ow.decimal("COMMISSION", t.Commission)
+	ow.decimal("TOTAL", t.Total)
+
+	if wrapper != "" {
+		ow.close(wrapper)
+	}
+	ow.close(t.Kind)
+}
+
+// writeSecurities writes a <SECLISTMSGSRSV1> holding the securities, ordered
+// by unique id.
+func (ow *ofxWriter) writeSecurities(securities map[string]Security) {
+	ids := make([]string, 0, len(securities))
+	for id := range securities {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	ow.open("SECLISTMSGSRSV1")
+	ow.open("SECLIST")
+	for _, id := range ids {
+		s := securities[id]
+		ow.open(s.Kind)
+		ow.open("SECINFO")
+		ow.open("SECID")
+		ow.elem("UNIQUEID", s.UniqueID)
+		ow.elem("UNIQUEIDTYPE", s.UniqueIDType)
+		ow.close("SECID")
+		ow.elem("SECNAME", s.Name)
+		ow.elem("TICKER", s.Ticker)
+		ow.float("UNITPRICE", s.UnitPrice)
+		ow.close("SECINFO")
+		ow.close(s.Kind)
+	}
+	ow.close("SECLIST")
+	ow.close("SECLISTMSGSRSV1")
+}
@@ -0,0 +1,51 @@
+package ofx
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestWriteOFXRoundTrip(t *testing.T) {
+	fixtures := []string{
+		"testdata/v103.ofx",
+		"testdata/sgml.ofx",
+		"testdata/creditcard.ofx",
+		"testdata/investment.ofx",
+		"testdata/currency.ofx",
+		"testdata/institution.ofx",
+		"testdata/period.ofx",
+		"testdata/balancedates.ofx",
+		"testdata/ballist.ofx",
+		"testdata/payee.ofx",
+		"testdata/signon.ofx",
+		"testdata/pending.xml",
+		"testdata/nofitid.ofx",
+		"testdata/seclist.ofx",
+		"testdata/transfer.ofx",
+	}
+
+	for _, name := range fixtures {
+		expected := parseFile(t, name)
+
+		var buf bytes.Buffer
+		if err := WriteOFX(&buf, expected); err != nil {
+			t.Fatalf("%s: %v\n", name, err)
+		}
+
+		actual, err := Parse(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("%s: failed to parse written OFX: %v\n%s\n", name, err, buf.String())
+		}
+
+		if actual.Header.Version != "220" {
+			t.Errorf("%s: wrong written version. Expected: 220 Actual: %s\n", name, actual.Header.Version)
+		}
+
+		// The written header is always OFX 2.x; everything else must survive.
+		expected.Header, actual.Header = Header{}, Header{}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: round trip differs.\nExpected: %s\nActual:   %s\nOFX:\n%s\n", name, expected, actual, buf.String())
+		}
+	}
+}